@@ -0,0 +1,227 @@
+// Package chatlog is the logging of the server and the clients.
+//
+// Every event is logged once, with a readable message and typed fields: the kind of event,
+// the participant, its ClientID, the room and the clock. The file gets JSON lines and is rotated
+// by size or age instead of being cleared on every start. The console gets the readable message
+// by default, so the chat looks the same as before, and can be turned to text or JSON too.
+//
+//	chatlog.Event(chatlog.Join).Participant(name).ClientID(id).Room(room).Infof("Participant %s joined %s", name, room)
+package chatlog
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Kind is what happened, it goes in the "event" field of every record.
+type Kind string
+
+const (
+	Startup    Kind = "startup"    // the server or client starts, listens or dials
+	Shutdown   Kind = "shutdown"   // the server stops
+	Connect    Kind = "connect"    // a participant registers or resumes its session
+	Disconnect Kind = "disconnect" // a participant's session ends
+	Join       Kind = "join"       // a participant joins a room
+	Leave      Kind = "leave"      // a participant leaves a room
+	Message    Kind = "message"    // a Text is broadcast or shown
+	Direct     Kind = "direct"     // a private message
+	Presence   Kind = "presence"   // the answer to /who
+	Rejected   Kind = "rejected"   // a message was not let through
+	Flood      Kind = "flood"      // a participant was warned, muted or disconnected for flooding
+	Ordering   Kind = "ordering"   // resends, gaps and held back messages
+	History    Kind = "history"    // reading and writing the kept broadcasts
+	Queue      Kind = "queue"      // the send queues of the server
+	Auth       Kind = "auth"       // logging in
+	Connection Kind = "connection" // the connection to the server, reconnects included
+	Input      Kind = "input"      // reading what the participant types
+	Admin      Kind = "admin"      // an operator used the Admin service
+)
+
+// Entry is a log record being put together, get one from Event.
+type Entry struct {
+	attrs []slog.Attr
+}
+
+// Event starts a record of the given kind.
+func Event(kind Kind) *Entry {
+	return &Entry{attrs: []slog.Attr{slog.String("event", string(kind))}}
+}
+
+// Participant is the name of the participant the event is about.
+func (e *Entry) Participant(name string) *Entry {
+	return e.Attr(slog.String("participant", name))
+}
+
+// ClientID is the ClientID of the participant the event is about.
+func (e *Entry) ClientID(id int32) *Entry {
+	return e.Attr(slog.Int("client_id", int(id)))
+}
+
+// Room is the room the event happened in.
+func (e *Entry) Room(room string) *Entry {
+	return e.Attr(slog.String("room", room))
+}
+
+// VectorClock is the vector clock at the event, ClientID -> count.
+func (e *Entry) VectorClock(clock map[int32]int32) *Entry {
+	return e.Attr(slog.Any("vector_clock", clock))
+}
+
+// Lamport is the Lamport timestamp at the event.
+func (e *Entry) Lamport(t int32) *Entry {
+	return e.Attr(slog.Int("lamport", int(t)))
+}
+
+// Sequence is the position of a broadcast in its room.
+func (e *Entry) Sequence(n int64) *Entry {
+	return e.Attr(slog.Int64("sequence", n))
+}
+
+// Err is the error that went with the event.
+func (e *Entry) Err(err error) *Entry {
+	return e.Attr(slog.String("error", err.Error()))
+}
+
+// Attr adds any other field.
+func (e *Entry) Attr(attr slog.Attr) *Entry {
+	e.attrs = append(e.attrs, attr)
+	return e
+}
+
+func (e *Entry) Debugf(format string, args ...any) { e.log(slog.LevelDebug, format, args) }
+func (e *Entry) Infof(format string, args ...any)  { e.log(slog.LevelInfo, format, args) }
+func (e *Entry) Warnf(format string, args ...any)  { e.log(slog.LevelWarn, format, args) }
+func (e *Entry) Errorf(format string, args ...any) { e.log(slog.LevelError, format, args) }
+
+// Fatalf logs at error level and exits.
+func (e *Entry) Fatalf(format string, args ...any) {
+	e.log(slog.LevelError, format, args)
+	os.Exit(1)
+}
+
+func (e *Entry) log(level slog.Level, format string, args []any) {
+	slog.Default().LogAttrs(context.Background(), level, fmt.Sprintf(format, args...), e.attrs...)
+}
+
+// Config says where the logs go, set it up with Flags.
+type Config struct {
+	File          string        // JSON lines go here, empty for no file
+	FileLevel     slog.Level    // the least important records that go in the file
+	MaxSize       int           // in MB, the file is rotated before it grows past this, 0 for no limit
+	MaxAge        time.Duration // the file is rotated once it is this old, 0 for no limit
+	Keep          int           // how many rotated files are kept
+	ConsoleLevel  slog.Level    // the least important records shown on the console
+	ConsoleFormat string        // plain (only the message), text, json or off
+}
+
+// Flags registers the logging flags. file is the default log file, which can also be set after
+// flag.Parse if it depends on other flags.
+func Flags(file string) *Config {
+	c := &Config{}
+	flag.StringVar(&c.File, "log-file", file, "File to write JSON lines logs to, empty for none")
+	flag.TextVar(&c.FileLevel, "log-level", slog.LevelInfo, "Least important records written to the log file: debug, info, warn or error")
+	flag.IntVar(&c.MaxSize, "log-max-size", 10, "Start a new log file when it would grow past this many MB, 0 for no limit")
+	flag.DurationVar(&c.MaxAge, "log-max-age", 0, "Start a new log file when it is this old, 0 for no limit")
+	flag.IntVar(&c.Keep, "log-keep", 5, "How many old log files to keep")
+	flag.TextVar(&c.ConsoleLevel, "console-level", slog.LevelInfo, "Least important records shown on the console: debug, info, warn or error")
+	flag.StringVar(&c.ConsoleFormat, "console-format", "plain", "How records are shown on the console: plain, text, json or off")
+	return c
+}
+
+// Setup makes the configured logging the default slog logger. The returned closer closes the log file.
+// A log file left from an earlier run is rotated first, so every run starts a file of its own.
+func Setup(c *Config) (io.Closer, error) {
+	var handlers []slog.Handler
+	switch c.ConsoleFormat {
+	case "plain":
+		handlers = append(handlers, &plainHandler{out: os.Stdout, level: c.ConsoleLevel, mutex: &sync.Mutex{}})
+	case "text":
+		handlers = append(handlers, slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: c.ConsoleLevel}))
+	case "json":
+		handlers = append(handlers, slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: c.ConsoleLevel}))
+	case "off":
+	default:
+		return nil, fmt.Errorf("unknown console format %q, use plain, text, json or off", c.ConsoleFormat)
+	}
+
+	var closer io.Closer = io.NopCloser(nil)
+	if c.File != "" {
+		file, err := openRotating(c.File, int64(c.MaxSize)<<20, c.MaxAge, c.Keep)
+		if err != nil {
+			return nil, err
+		}
+		closer = file
+		handlers = append(handlers, slog.NewJSONHandler(file, &slog.HandlerOptions{Level: c.FileLevel}))
+	}
+
+	slog.SetDefault(slog.New(tee(handlers)))
+	return closer, nil
+}
+
+// plainHandler writes only the message, the way the chat has always looked.
+type plainHandler struct {
+	out   io.Writer
+	level slog.Level
+	mutex *sync.Mutex
+}
+
+func (h *plainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *plainHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	_, err := fmt.Fprintln(h.out, r.Message)
+	return err
+}
+
+func (h *plainHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *plainHandler) WithGroup(string) slog.Handler      { return h }
+
+// tee hands every record to all the handlers that want it.
+type tee []slog.Handler
+
+func (t tee) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t tee) Handle(ctx context.Context, r slog.Record) error {
+	var first error
+	for _, h := range t {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (t tee) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(tee, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t tee) WithGroup(name string) slog.Handler {
+	handlers := make(tee, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
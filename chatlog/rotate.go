@@ -0,0 +1,158 @@
+package chatlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stampPattern matches the time in the name of a moved aside file, and not a participant called alice-2.
+const stampPattern = "-[0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9]-[0-9][0-9][0-9][0-9][0-9][0-9]*"
+
+// rotating is a log file that is moved aside and started over when it gets too big or too old.
+// Old files are named after the time they were moved aside, like log_server-20231101-142500.txt.
+type rotating struct {
+	mutex   sync.Mutex
+	path    string
+	maxSize int64         // 0 for no limit
+	maxAge  time.Duration // 0 for no limit
+	keep    int
+	file    *os.File // nil when a rotation could not open the new file, the next write tries again
+	closed  bool
+	size    int64
+	opened  time.Time
+}
+
+// openRotating opens a new log file at path, after moving aside what an earlier run left there.
+func openRotating(path string, maxSize int64, maxAge time.Duration, keep int) (*rotating, error) {
+	r := &rotating{path: path, maxSize: maxSize, maxAge: maxAge, keep: keep}
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		if err := r.moveAside(); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotating) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	tooBig := r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize
+	tooOld := r.maxAge > 0 && time.Since(r.opened) > r.maxAge
+	if tooBig || tooOld {
+		if err := r.rotate(); err != nil {
+			// keep writing to the old file rather than losing the record
+			fmt.Fprintf(os.Stderr, "Failed to rotate %s: %v\n", r.path, err)
+		}
+		if r.file == nil {
+			return 0, os.ErrClosed
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotating) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.closed = true
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// rotate closes the current file, moves it aside and starts a new one. The file is closed first because
+// Windows can't rename an open file. Whether moving it aside worked or not, the file at r.path is opened
+// again, so a failed rename only means the old file is written to for a while longer.
+// The caller must hold r.mutex.
+func (r *rotating) rotate() error {
+	closeErr := r.file.Close()
+	r.file = nil
+	moveErr := r.moveAside()
+	if err := r.open(); err != nil {
+		return err
+	}
+	if moveErr != nil {
+		return moveErr
+	}
+	return closeErr
+}
+
+// open starts writing to the file at r.path.
+// The caller must hold r.mutex, or be the only one with r.
+func (r *rotating) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	r.opened = time.Now()
+	return nil
+}
+
+// moveAside renames the file at r.path after the current time and removes the oldest
+// files beyond r.keep.
+func (r *rotating) moveAside() error {
+	ext := filepath.Ext(r.path)
+	base := strings.TrimSuffix(r.path, ext)
+	stamp := time.Now().Format("20060102-150405")
+
+	old := fmt.Sprintf("%s-%s%s", base, stamp, ext)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(old); os.IsNotExist(err) {
+			break
+		}
+		// rotated more than once in the same second
+		old = fmt.Sprintf("%s-%s.%d%s", base, stamp, i, ext)
+	}
+	if err := os.Rename(r.path, old); err != nil {
+		return err
+	}
+
+	rotated, err := filepath.Glob(base + stampPattern + ext)
+	if err != nil {
+		return err
+	}
+	// the file that was written to last is kept longest
+	modified := make(map[string]time.Time)
+	for _, name := range rotated {
+		if info, err := os.Stat(name); err == nil {
+			modified[name] = info.ModTime()
+		}
+	}
+	sort.Slice(rotated, func(i, j int) bool { return modified[rotated[i]].Before(modified[rotated[j]]) })
+	for len(rotated) > r.keep {
+		if err := os.Remove(rotated[0]); err != nil {
+			return err
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
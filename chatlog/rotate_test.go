@@ -0,0 +1,53 @@
+package chatlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log_test.txt")
+	r, err := openRotating(path, 100, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	line := strings.Repeat("x", 39) + "\n"
+	for i := 0; i < 20; i++ {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	rotated, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "log_test"+stampPattern+".txt"))
+	if len(rotated) != 2 {
+		t.Errorf("kept %d rotated files, want 2: %v", len(rotated), rotated)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() > 100 {
+		t.Errorf("current file: %v, %v", info, err)
+	}
+}
+
+// When the file can't be moved aside, here because someone deleted it, logging goes on instead of failing from then on.
+func TestRotateFailureKeepsLogging(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log_test.txt")
+	r, err := openRotating(path, 0, time.Millisecond, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	os.Remove(path)
+	time.Sleep(5 * time.Millisecond)
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("write after a failed rotation: %v", err)
+		}
+	}
+	if content, err := os.ReadFile(path); err != nil || !strings.Contains(string(content), "second") {
+		t.Errorf("the log has %q, %v", content, err)
+	}
+}
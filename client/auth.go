@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	"golang.org/x/term"
+	"google.golang.org/grpc"
+)
+
+var password = flag.String("password", "", "Password to log in with, if the server uses a password file")
+var askPassword = flag.Bool("ask-password", false, "Ask for the password when starting instead of taking it as a flag")
+var token = flag.String("token", "", "Bearer token to log in with, if the server uses signed tokens")
+
+// authCredentials puts the "authorization" metadata on every call to the server.
+type authCredentials struct {
+	authorization string
+}
+
+func (c authCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": c.authorization}, nil
+}
+
+// RequireTransportSecurity is false so logging in works without TLS, but then the password can be read on the way.
+func (c authCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// authDialOption returns the dial option that logs in with the token or password from the flags,
+// or nil if none is given.
+func authDialOption() grpc.DialOption {
+	if *askPassword {
+		input, err := readPassword()
+		if err != nil {
+			chatlog.Event(chatlog.Auth).Err(err).Fatalf("Failed to read the password: %v", err)
+		}
+		*password = input
+	}
+
+	switch {
+	case *token != "":
+		return grpc.WithPerRPCCredentials(authCredentials{authorization: "Bearer " + *token})
+	case *password != "":
+		basic := base64.StdEncoding.EncodeToString([]byte(*clientsName + ":" + *password))
+		return grpc.WithPerRPCCredentials(authCredentials{authorization: "Basic " + basic})
+	}
+	return nil
+}
+
+// readPassword asks for the password without showing what is typed. When the input is not a terminal,
+// like when it is piped in, the password is the first line.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		input, err := stdin.ReadString('\n')
+		if err != nil && input == "" {
+			return "", err
+		}
+		return strings.TrimSpace(input), nil
+	}
+	fmt.Print("Password: ")
+	typed, err := term.ReadPassword(fd)
+	fmt.Println()
+	return strings.TrimSpace(string(typed)), err
+}
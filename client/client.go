@@ -5,32 +5,47 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"hash/fnv"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	"github.com/JonasSkjodt/chitty-chat/content"
+	"github.com/JonasSkjodt/chitty-chat/dial"
 	// this has to be the same as the go.mod module,
 	// followed by the path to the folder the proto file is in.
 	// inspired by https://github.com/PatrickMatthiesen/DSYS-gRPC-template and https://articles.wesionary.team/grpc-console-chat-application-in-go-dd77a29bb5c3
 	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
-
+	"github.com/JonasSkjodt/chitty-chat/vclock"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // Same principle as in client. Flags allows for user specific arguments/values
 var clientsName = flag.String("name", "default", "Senders name")
-var serverPort = flag.String("server", "5400", "Tcp server")
+var serverAddr = flag.String("server", "5400", "Server address as host:port, e.g. chat.example.com:5400 or 192.168.1.20:5400. A port alone means this machine")
+var tlsConfig = dial.TLSFlags()
+var logConfig = chatlog.Flags("log_<name>.txt")
+var holdTimeout = flag.Duration("holdback", 5*time.Second, "Warn when a message waits this long for the messages it depends on")
 
 var ServerConn *grpc.ClientConn //the server connection
 var chatServer gRPC.ChatClient  // new chat server client
 
-var vectorClock = []int32{0, 0} // vector clock for the client
-var clientID = -1               // clientID is set to 1 by default
-var hasher = fnv.New32()
+var stdin = bufio.NewReader(os.Stdin) // shared by the password prompt and the chat input
+var sendMutex sync.Mutex              // guards chatStream, clientID and sessionToken, which change when we reconnect, and sends one message at a time
+var chatStream gRPC.Chat_MessageStreamClient
+var clientID int32 = -1      // clientID is set by the server when joining
+var sessionToken string      // session token handed out by ConnectToServer
+var leaving bool             // set once we leave, so a closed stream is not taken for a lost connection
+var shutdown *gRPC.Shutdown  // the notice of the server, if it told us it is shutting down
+var clockMode gRPC.ClockMode // the kind of clock the server uses, the same in every room
+var maxLength int32 = 128    // most characters the server takes in a message, guarded by sendMutex
 
 func main() {
 	//parse flag/arguments
@@ -38,7 +53,7 @@ func main() {
 
 	fmt.Println("--- CLIENT APP ---")
 
-	//log to file instead of console
+	//log to the console and a log file
 	f := setLog()
 	defer f.Close()
 
@@ -48,41 +63,47 @@ func main() {
 	//defer SendMessage("exit", ChatStream)
 	defer ServerConn.Close()
 
-	ChatStream, err := chatServer.MessageStream(context.Background())
+	state := joinChat()
+
+	// the stream is bound to our session through the session-token metadata
+	ChatStream, err := chatServer.MessageStream(sessionContext())
 	if err != nil {
-		fmt.Printf("Error on receive: %v \n", err)
-		log.Fatalf("Error on receive: %v", err)
+		chatlog.Event(chatlog.Connection).Err(err).Fatalf("Error on receive: %v", err)
 	}
-	hasher.Write([]byte(*clientsName))
+	chatStream = ChatStream
 
-	// Client will connect with a timestamp of 0 because client has not received it's id.
-	// This will be updated when the reponse from the server is received.
-	SendMessage(fmt.Sprint(hasher.Sum32()), ChatStream)
+	// we start out in the default room
+	addRoom(state)
+	go watchRooms()
 
 	//start the biding
 
-	go listenForMessages(ChatStream)
-	parseInput(ChatStream)
+	go listenForMessages()
+	parseInput()
 }
 
 // connect to server
 func ConnectToServer() {
 
 	//dial options
-	//the server is not using TLS, so we use insecure credentials
-	//(should be fine for local testing but not in the real world)
+	creds, err := tlsConfig.TransportCredentials()
+	if err != nil {
+		chatlog.Event(chatlog.Startup).Err(err).Fatalf("Failed to set up TLS: %v", err)
+	}
 	opts := []grpc.DialOption{
 		grpc.WithBlock(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
+	}
+	if auth := authDialOption(); auth != nil {
+		opts = append(opts, auth)
 	}
 
 	//dial the server, with the flag "server", to get a connection to it
-	fmt.Printf("client %s: Attempts to dial on port %s\n", *clientsName, *serverPort)
-	log.Printf("client %s: Attempts to dial on port %s\n", *clientsName, *serverPort)
-	conn, err := grpc.Dial(fmt.Sprintf(":%s", *serverPort), opts...)
+	target := dial.Target(*serverAddr)
+	chatlog.Event(chatlog.Startup).Participant(*clientsName).Attr(slog.String("address", target)).Infof("client %s: Attempts to dial %s", *clientsName, target)
+	conn, err := grpc.Dial(target, opts...)
 	if err != nil {
-		fmt.Printf("Fail to Dial : %v \n", err)
-		log.Printf("Fail to Dial : %v", err)
+		chatlog.Event(chatlog.Startup).Err(err).Errorf("Fail to Dial : %v", err)
 		return
 	}
 
@@ -91,44 +112,121 @@ func ConnectToServer() {
 	// and prints rather or not the connection was is READY
 	chatServer = gRPC.NewChatClient(conn)
 	ServerConn = conn
-	fmt.Println("the connection is: ", conn.GetState().String())
-	log.Println("the connection is: ", conn.GetState().String())
+	chatlog.Event(chatlog.Startup).Infof("the connection is: %s", conn.GetState().String())
+}
+
+// joinChat registers the client with the server, which hands back our ClientID,
+// a session token and the state of the room every participant starts out in.
+func joinChat() *gRPC.RoomState {
+	reply, err := chatServer.ConnectToServer(context.Background(), &gRPC.ClientName{ClientName: *clientsName})
+	if err != nil {
+		chatlog.Event(chatlog.Connect).Participant(*clientsName).Err(err).Fatalf("Failed to join chitty-chat: %v", err)
+	}
+	clientID = reply.ClientID
+	sessionToken = reply.SessionToken
+	clockMode = reply.ClockMode
+	if reply.MaxLength > 0 {
+		maxLength = reply.MaxLength
+	}
+
+	chatlog.Event(chatlog.Connect).Participant(*clientsName).ClientID(clientID).Room(reply.Room.Room).Infof("Joined chitty-chat as participant %d in %s using %s clocks", clientID, reply.Room.Room, reply.ClockMode)
+	return reply.Room
+}
+
+// showHistory asks the server for the page of messages in the room before the oldest one shown so far.
+func showHistory(view *roomView) {
+	stream, err := chatServer.History(sessionContext(), &gRPC.HistoryRequest{Room: view.name, BeforeSequence: view.historyBefore, Limit: 20})
+	if err != nil {
+		chatlog.Event(chatlog.History).Room(view.name).Err(err).Errorf("Failed to get the history: %v", err)
+		return
+	}
+
+	var page []*gRPC.ChatMessage
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			chatlog.Event(chatlog.History).Room(view.name).Err(err).Errorf("Failed to get the history: %v", err)
+			return
+		}
+		page = append(page, msg)
+	}
+	if len(page) == 0 {
+		fmt.Println("No older messages")
+		return
+	}
+	printHistory(view, page)
+}
+
+// printHistory shows old broadcasts of the room with the clock they were sent with, and moves the /history cursor back.
+func printHistory(view *roomView, msgs []*gRPC.ChatMessage) {
+	for _, msg := range msgs {
+		printMessage(msg, messageTimestamp(clockMode, msg))
+	}
+	view.historyBefore = min(view.historyBefore, msgs[0].Sequence)
+}
+
+// messageTimestamp is the clock a message was sent with, for printing.
+func messageTimestamp(mode gRPC.ClockMode, msg *gRPC.ChatMessage) string {
+	if mode == gRPC.ClockMode_LAMPORT {
+		return fmt.Sprintf("lamport timestamp: %d", msg.LamportTimestamp)
+	}
+	return fmt.Sprintf("vector clock: %v", vclock.Clock(msg.VectorClock))
+}
+
+// leaveChat ends our session on the server, the server tells the other participants.
+func leaveChat() {
+	sendMutex.Lock()
+	leaving = true
+	token := sessionToken
+	sendMutex.Unlock()
+
+	_, err := chatServer.DisconnectFromServer(context.Background(), &gRPC.Session{ClientName: *clientsName, SessionToken: token})
+	if err != nil {
+		chatlog.Event(chatlog.Disconnect).Err(err).Errorf("Failed to leave chitty-chat: %v", err)
+	}
 }
 
-func parseInput(stream gRPC.Chat_MessageStreamClient) {
-	reader := bufio.NewReader(os.Stdin)
+func parseInput() {
 	fmt.Println("Welcome to Chitty Chat!")
 	fmt.Println("--------------------")
 
 	//Infinite loop to listen for clients input.
 	for {
 		//Read input into var input and any errors into err
-		input, err := reader.ReadString('\n')
+		input, err := stdin.ReadString('\n')
 		if err != nil {
-			fmt.Printf("%v \n", err)
-			log.Fatal(err)
+			chatlog.Event(chatlog.Input).Err(err).Fatalf("%v", err)
 		}
 		input = strings.TrimSpace(input) //Trim input
 
-		// message must be under 128 characters long
-		if len(input) > 128 {
-			println("Message is too long. Your message must be under 128 characters long")
-			continue
-		}
-
 		if !conReady(chatServer) {
-			fmt.Printf("Client %s: something was wrong with the connection to the server :(", *clientsName)
-			log.Printf("Client %s: something was wrong with the connection to the server :(", *clientsName)
+			chatlog.Event(chatlog.Connection).Participant(*clientsName).Warnf("Client %s: something was wrong with the connection to the server :(", *clientsName)
 			continue
 		}
 
 		if input == "exit" {
-			SendMessage("Participant "+*clientsName+" left chitty-chat", stream)
-			//chatServer.DisconnectFromServer(stream.Context(), &gRPC.ClientName{ClientName: *clientsName})
-			time.Sleep(1 * time.Second)
+			leaveChat()
 			os.Exit(1)
+		} else if input == "/rooms" {
+			listRooms()
+		} else if room, ok := strings.CutPrefix(input, "/join "); ok {
+			joinRoom(strings.TrimSpace(room))
+		} else if room, ok := strings.CutPrefix(input, "/leave "); ok {
+			leaveRoom(strings.TrimSpace(room))
+		} else if direct, ok := strings.CutPrefix(input, "/msg "); ok {
+			sendDirect(direct)
+		} else if view := currentView(); view == nil {
+			fmt.Println("You are not in any room, use /join #room")
+		} else if input == "/history" {
+			showHistory(view)
+		} else if input == "/who" {
+			// asks the server who is in the room, the answer arrives as a Presence message
+			sendOnStream(&gRPC.ChatMessage{ClientName: *clientsName, Room: view.name, Payload: &gRPC.ChatMessage_Presence{Presence: &gRPC.Presence{}}})
 		} else {
-			SendMessage(input, stream)
+			SendMessage(view, input)
 		}
 	}
 }
@@ -138,79 +236,169 @@ func conReady(s gRPC.ChatClient) bool {
 	return ServerConn.GetState().String() == "READY"
 }
 
-// sets the logger to use a log.txt file instead of the console
-func setLog() *os.File {
-	if err := os.Truncate("log_"+*clientsName+".txt", 0); err != nil {
-		fmt.Printf("Failed to truncate: %v \n", err)
-		log.Printf("Failed to truncate: %v", err)
+// setLog logs to the console and, as JSON lines, to the -log-file with <name> filled in.
+// The log of an earlier run is rotated instead of cleared.
+func setLog() io.Closer {
+	logConfig.File = strings.ReplaceAll(logConfig.File, "<name>", *clientsName)
+	logs, err := chatlog.Setup(logConfig)
+	if err != nil {
+		fmt.Printf("Failed to set up logging: %v \n", err)
+		os.Exit(2)
 	}
+	return logs
+}
 
-	f, err := os.OpenFile("log_"+*clientsName+".txt", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		fmt.Printf("error opening file: %v", err)
-		log.Fatalf("error opening file: %v", err)
+// roomEvent starts a log record about a message in a room, with the clock and sequence number it came with.
+func roomEvent(kind chatlog.Kind, msg *gRPC.ChatMessage) *chatlog.Entry {
+	e := chatlog.Event(kind).Room(msg.Room).Sequence(msg.Sequence)
+	if clockMode == gRPC.ClockMode_LAMPORT {
+		return e.Lamport(msg.LamportTimestamp)
 	}
-	log.SetOutput(f)
-	return f
+	return e.VectorClock(msg.VectorClock)
 }
 
-func SendMessage(content string, stream gRPC.Chat_MessageStreamClient) {
-	if clientID != -1 {
-		vectorClock[clientID]++
+func SendMessage(view *roomView, text string) {
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+
+	// a message the server rejects would still have ticked our clock, so it is checked here first
+	text, rejected := content.Clean(text, int(maxLength))
+	if rejected != nil {
+		printRejected(rejected)
+		return
 	}
 	message := &gRPC.ChatMessage{
-		Content:     content,
-		ClientName:  *clientsName,
-		VectorClock: vectorClock,
+		ClientName: *clientsName,
+		Room:       view.name,
+		Payload:    &gRPC.ChatMessage_Text{Text: &gRPC.Text{Content: text}},
+	}
+	// sending is our event, so only our own slot in the rooms clock is ticked
+	message.ClientID = clientID
+	view.delivery.send(clientID, message)
+	chatStream.Send(message)
+}
+
+// sendDirect sends "<name> <text>" as a private message, a number instead of a name is taken as a ClientID.
+func sendDirect(input string) {
+	recipient, text, ok := strings.Cut(strings.TrimSpace(input), " ")
+	text = strings.TrimSpace(text)
+	if !ok || text == "" {
+		fmt.Println("Usage: /msg <name> <text>")
+		return
+	}
+
+	direct := &gRPC.Direct{RecipientName: recipient, Content: text}
+	if id, err := strconv.ParseInt(recipient, 10, 32); err == nil {
+		direct = &gRPC.Direct{RecipientID: int32(id), Content: text}
 	}
 
-	stream.Send(message)
+	// the message comes back to us on the stream, so it is printed in the same way as for the recipient
+	_, err := chatServer.SendDirect(sessionContext(), direct)
+	if rejected := content.Rejection(err); rejected != nil {
+		printRejected(rejected)
+	} else if err != nil {
+		chatlog.Event(chatlog.Direct).Attr(slog.String("recipient", recipient)).Err(err).Warnf("Failed to send the message to %s: %v", recipient, status.Convert(err).Message())
+	}
+}
+
+// sendOnStream sends a message on the stream, which both the input and the listening goroutine use.
+func sendOnStream(msg *gRPC.ChatMessage) {
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+	chatStream.Send(msg)
+}
+
+// sessionContext returns a context with our session-token metadata, for the calls that need it.
+func sessionContext() context.Context {
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+	return metadata.AppendToOutgoingContext(context.Background(), "session-token", sessionToken)
+}
+
+// ownID returns our ClientID, it can change when we reconnect.
+func ownID() int32 {
+	sendMutex.Lock()
+	defer sendMutex.Unlock()
+	return clientID
 }
 
 // watch the god
-func listenForMessages(stream gRPC.Chat_MessageStreamClient) {
+// received messages go through the sequencer and the hold-back queue of their room, which print them in order.
+// When the stream breaks we reconnect and carry on with the new one.
+func listenForMessages() {
 	for {
-		time.Sleep(1 * time.Second)
-		if stream != nil {
-			msg, err := stream.Recv()
-			if err == io.EOF {
-				fmt.Printf("Error: io.EOF in listenForMessages in client.go \n")
-				log.Printf("Error: io.EOF in listenForMessages in client.go")
-				break
-			}
-			if err != nil {
-				fmt.Printf("%v \n", err)
-				log.Fatalf("%v", err)
+		sendMutex.Lock()
+		stream, left := chatStream, leaving
+		sendMutex.Unlock()
+		if left {
+			return
+		}
+
+		msg, err := stream.Recv()
+		if err != nil {
+			sendMutex.Lock()
+			left, notice := leaving, shutdown
+			shutdown = nil
+			sendMutex.Unlock()
+			if left {
+				return
 			}
-			if strings.Contains(msg.Content, *clientsName+" joined chitty-chat") {
-				// Updates the clientID
-				clientID = int(msg.ClientID)
+			if notice != nil {
+				// the server told us it was going away, so this is no surprise
+				waitForRestart(notice)
 			}
-
-			//Updates the clients vector clock
-			updateVectorClock(msg.VectorClock)
-			if msg.ClientName != *clientsName {
-				fmt.Printf("%s: \"%s\" at lamport timestamp: %d \n", msg.ClientName, msg.Content, vectorClock)
-				log.Printf("%s: \"%s\" at lamport timestamp: %d", msg.ClientName, msg.Content, vectorClock)
+			if status.Code(err) == codes.PermissionDenied {
+				// kicked or banned by an operator, or disconnected for flooding, coming back on our own would only be refused or kicked again
+				chatlog.Event(chatlog.Connection).Err(err).Fatalf("Removed from chitty-chat: %v", status.Convert(err).Message())
 			}
-
+			reconnect(err)
+			continue
 		}
+		receive(msg)
 	}
 }
 
-func updateVectorClock(msgVectorClock []int32) {
-	for i := 0; i < len(msgVectorClock); i++ {
-		// Add values to vectorClock if different lengths
-		if len(msgVectorClock) >= len(vectorClock) {
-			var lenDiff int = len(msgVectorClock) - len(vectorClock)
-			for j := 0; j < lenDiff; j++ {
-				vectorClock = append(vectorClock, 0)
-			}
+// printRejected tells why a message was not sent, whether we or the server caught it.
+func printRejected(rejected *gRPC.Rejected) {
+	chatlog.Event(chatlog.Rejected).Attr(slog.String("reason", rejected.Reason.String())).Warnf("Message not sent: %s", rejected.Detail)
+}
+
+// printMessage shows a delivered message according to its payload, with our clock of the room after delivering it
+func printMessage(msg *gRPC.ChatMessage, timestamp string) {
+	switch payload := msg.Payload.(type) {
+	case *gRPC.ChatMessage_Text:
+		// our own messages are shown too, in the place the server put them
+		roomEvent(chatlog.Message, msg).Participant(msg.ClientName).ClientID(msg.ClientID).Infof("[%s] %s: \"%s\" at %s", msg.Room, msg.ClientName, payload.Text.Content, timestamp)
+	case *gRPC.ChatMessage_Join:
+		roomEvent(chatlog.Join, msg).Participant(payload.Join.ClientName).ClientID(payload.Join.ClientID).Infof("Participant %s joined %s at %s", payload.Join.ClientName, msg.Room, timestamp)
+	case *gRPC.ChatMessage_Leave:
+		left := "left " + msg.Room
+		if payload.Leave.Reason != "" {
+			left = fmt.Sprintf("left %s (%s)", msg.Room, payload.Leave.Reason)
+		}
+		roomEvent(chatlog.Leave, msg).Participant(payload.Leave.ClientName).ClientID(payload.Leave.ClientID).Attr(slog.String("reason", payload.Leave.Reason)).Infof("Participant %s %s at %s", payload.Leave.ClientName, left, timestamp)
+	case *gRPC.ChatMessage_Direct:
+		// private messages are not part of any room and have no clock
+		if msg.ClientID == ownID() {
+			chatlog.Event(chatlog.Direct).Participant(msg.ClientName).ClientID(msg.ClientID).Attr(slog.String("recipient", payload.Direct.RecipientName)).Infof("[private] you -> %s: \"%s\"", payload.Direct.RecipientName, payload.Direct.Content)
+		} else {
+			chatlog.Event(chatlog.Direct).Participant(msg.ClientName).ClientID(msg.ClientID).Attr(slog.String("recipient", payload.Direct.RecipientName)).Infof("[private] %s -> you: \"%s\"", msg.ClientName, payload.Direct.Content)
+		}
+	case *gRPC.ChatMessage_Rejected:
+		printRejected(payload.Rejected)
+	case *gRPC.ChatMessage_Announcement:
+		if payload.Announcement.Room != "" {
+			chatlog.Event(chatlog.Message).Participant(msg.ClientName).Room(payload.Announcement.Room).Infof("*** Announcement to %s: %s", payload.Announcement.Room, payload.Announcement.Content)
+		} else {
+			chatlog.Event(chatlog.Message).Participant(msg.ClientName).Infof("*** Announcement: %s", payload.Announcement.Content)
 		}
-		// Compare and update values
-		if vectorClock[i] < msgVectorClock[i] {
-			vectorClock[i] = msgVectorClock[i]
+	case *gRPC.ChatMessage_Shutdown:
+		back := "it is not coming back"
+		if payload.Shutdown.RestartIn > 0 {
+			back = fmt.Sprintf("it should be back in %ds", payload.Shutdown.RestartIn)
 		}
+		chatlog.Event(chatlog.Shutdown).Attr(slog.Int("restart_in", int(payload.Shutdown.RestartIn))).Warnf("The server is shutting down: %s, %s", payload.Shutdown.Reason, back)
+	case *gRPC.ChatMessage_Presence:
+		chatlog.Event(chatlog.Presence).Room(msg.Room).Infof("In %s: %s", msg.Room, strings.Join(payload.Presence.Participants, ", "))
 	}
-	vectorClock[clientID]++
 }
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"github.com/JonasSkjodt/chitty-chat/vclock"
+)
+
+// heldMessage is a received message waiting for the messages it causally depends on.
+type heldMessage struct {
+	msg    *gRPC.ChatMessage
+	since  time.Time
+	warned bool
+}
+
+// holdBack delivers received messages in causal order.
+// A message from participant j is delivered once its clock has j's entry exactly one past what we have
+// delivered from j, and no other entry ahead of what we have delivered. Everything else waits.
+//
+// With LAMPORT clocks there is nothing to tell the dependencies by, so messages are delivered as the sequencer
+// passes them on. The servers sequence order is already a total order that respects causality, and sorting
+// again by stamp on every client would only let the clients disagree about it.
+type holdBack struct {
+	mutex   sync.Mutex
+	mode    gRPC.ClockMode
+	self    int32          // our ClientID, our own messages come back to us already counted
+	clock   vclock.Clock   // what has been delivered (or sent by us), per ClientID
+	lamport vclock.Lamport // our clock in LAMPORT mode
+	pending []*heldMessage
+	last    vclock.Clock                                  // the clock of the last message delivered from someone else
+	timeout time.Duration                                 // how long a message may wait before we warn about it
+	deliver func(msg *gRPC.ChatMessage, timestamp string) // called in causal order, with our clock after delivery
+}
+
+// newHoldBack starts from the clock of the room when we joined it, everything before that counts as delivered.
+func newHoldBack(state *gRPC.RoomState, mode gRPC.ClockMode, self int32, timeout time.Duration, deliver func(*gRPC.ChatMessage, string)) *holdBack {
+	return &holdBack{
+		mode:    mode,
+		self:    self,
+		clock:   vclock.Clock(state.VectorClock).Copy(),
+		lamport: vclock.Lamport(state.LamportTimestamp),
+		timeout: timeout,
+		deliver: deliver,
+	}
+}
+
+// send counts a broadcast of our own and puts our clock on the message.
+func (h *holdBack) send(id int32, msg *gRPC.ChatMessage) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.mode == gRPC.ClockMode_LAMPORT {
+		msg.LamportTimestamp = h.lamport.Tick()
+		return
+	}
+	h.clock.Tick(id)
+	msg.VectorClock = h.clock.Copy()
+}
+
+// timestamp is our current clock, for printing.
+// The caller must hold h.mutex.
+func (h *holdBack) timestamp() string {
+	if h.mode == gRPC.ClockMode_LAMPORT {
+		return fmt.Sprintf("lamport timestamp: %d", h.lamport)
+	}
+	return fmt.Sprintf("vector clock: %v", h.clock)
+}
+
+// receive holds the message back until it can be delivered, then delivers it and anything it unblocked.
+// Messages without a clock (like Presence answers) are not part of the causal order and are delivered at once,
+// and so are our own messages coming back from the server.
+func (h *holdBack) receive(msg *gRPC.ChatMessage) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.mode == gRPC.ClockMode_LAMPORT {
+		if msg.LamportTimestamp != 0 {
+			h.lamport.Witness(msg.LamportTimestamp)
+		}
+		h.deliver(msg, h.timestamp())
+		return
+	}
+	if len(msg.VectorClock) == 0 || msg.ClientID == h.self {
+		h.deliver(msg, h.timestamp())
+		return
+	}
+	if msg.VectorClock[msg.ClientID] <= h.clock[msg.ClientID] {
+		// we have delivered this one already
+		return
+	}
+	h.pending = append(h.pending, &heldMessage{msg: msg, since: time.Now()})
+
+	for delivered := true; delivered; {
+		delivered = false
+		for i, held := range h.pending {
+			if h.deliverable(held.msg) {
+				h.pending = append(h.pending[:i], h.pending[i+1:]...)
+				h.noteConcurrent(held.msg)
+				h.clock.Merge(held.msg.VectorClock)
+				h.forget(held.msg)
+				h.deliver(held.msg, h.timestamp())
+				delivered = true
+				break
+			}
+		}
+	}
+}
+
+// noteConcurrent logs when the message is concurrent with the one delivered before it. Other members may
+// well have shown the two the other way around, causal order doesn't say anything about them.
+// The caller must hold h.mutex.
+func (h *holdBack) noteConcurrent(msg *gRPC.ChatMessage) {
+	clock := vclock.Clock(msg.VectorClock)
+	if h.last != nil && clock.Compare(h.last) == vclock.Concurrent {
+		chatlog.Event(chatlog.Ordering).Participant(msg.ClientName).ClientID(msg.ClientID).Room(msg.Room).VectorClock(msg.VectorClock).Debugf("The message from %s (clock %v) is concurrent with the one before it (clock %v), other members may show them the other way around", msg.ClientName, clock, h.last)
+	}
+	h.last = clock
+}
+
+// forget drops the slot of a participant that joins or leaves the room. A ClientID that comes back,
+// after the participant reconnected, starts counting from zero again.
+// The caller must hold h.mutex.
+func (h *holdBack) forget(msg *gRPC.ChatMessage) {
+	switch payload := msg.Payload.(type) {
+	case *gRPC.ChatMessage_Join:
+		h.clock.Retire(payload.Join.ClientID)
+	case *gRPC.ChatMessage_Leave:
+		h.clock.Retire(payload.Leave.ClientID)
+	}
+}
+
+// deliverable tells whether everything the message depends on has been delivered.
+// The caller must hold h.mutex.
+func (h *holdBack) deliverable(msg *gRPC.ChatMessage) bool {
+	sender := msg.ClientID
+	for id, n := range msg.VectorClock {
+		if id == sender {
+			if n != h.clock[id]+1 {
+				return false
+			}
+		} else if n > h.clock[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// warnHeld warns once about every message that has waited longer than the timeout.
+func (h *holdBack) warnHeld(now time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, held := range h.pending {
+		if !held.warned && now.Sub(held.since) > h.timeout {
+			held.warned = true
+			chatlog.Event(chatlog.Ordering).Participant(held.msg.ClientName).ClientID(held.msg.ClientID).Room(held.msg.Room).VectorClock(held.msg.VectorClock).Warnf("A message from %s has been held back for %v, still waiting for earlier messages (clock %v, delivered %v)", held.msg.ClientName, h.timeout, vclock.Clock(held.msg.VectorClock), h.clock)
+		}
+	}
+}
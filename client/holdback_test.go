@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"github.com/JonasSkjodt/chitty-chat/vclock"
+)
+
+// causalRun makes up a chat between the participants: before each message the sender has caught up on some
+// of what was sent before it, so later messages depend on a random part of the earlier ones.
+func causalRun(rng *rand.Rand, participants int, messages int) []*gRPC.ChatMessage {
+	clocks := make([]vclock.Clock, participants+1)
+	caughtUp := make([]int, participants+1) // how many of the sent messages each participant has delivered
+	for id := range clocks {
+		clocks[id] = vclock.New()
+	}
+
+	var sent []*gRPC.ChatMessage
+	for i := 0; i < messages; i++ {
+		id := 1 + rng.Intn(participants)
+		for caughtUp[id] < len(sent) && rng.Intn(3) > 0 {
+			clocks[id].Merge(sent[caughtUp[id]].VectorClock)
+			caughtUp[id]++
+		}
+		clocks[id].Tick(int32(id))
+		sent = append(sent, &gRPC.ChatMessage{
+			ClientID:    int32(id),
+			ClientName:  fmt.Sprintf("client%d", id),
+			Room:        "#general",
+			Payload:     &gRPC.ChatMessage_Text{Text: &gRPC.Text{Content: fmt.Sprint(i)}},
+			VectorClock: clocks[id].Copy(),
+		})
+	}
+	return sent
+}
+
+// deliverShuffled hands the messages to a fresh holdBack in the given order and returns what it delivered.
+func deliverShuffled(arrivals []*gRPC.ChatMessage) []*gRPC.ChatMessage {
+	var delivered []*gRPC.ChatMessage
+	h := newHoldBack(&gRPC.RoomState{}, gRPC.ClockMode_VECTOR, 1000, time.Minute, func(msg *gRPC.ChatMessage, _ string) {
+		delivered = append(delivered, msg)
+	})
+	for _, msg := range arrivals {
+		h.receive(msg)
+	}
+	return delivered
+}
+
+// checkCausalOrder fails the test if a message was shown before one that happened before it.
+func checkCausalOrder(t *testing.T, delivered []*gRPC.ChatMessage) {
+	t.Helper()
+	for i, later := range delivered {
+		for _, earlier := range delivered[i+1:] {
+			if vclock.Clock(earlier.VectorClock).Compare(later.VectorClock) == vclock.Before {
+				t.Fatalf("%s %v was shown before %s %v, which happened before it", later.GetText().GetContent(), vclock.Clock(later.VectorClock), earlier.GetText().GetContent(), vclock.Clock(earlier.VectorClock))
+			}
+		}
+	}
+}
+
+// The network (or the server's queues) may reorder what we receive, the holdBack must still show it in causal order.
+func TestHoldBackReordered(t *testing.T) {
+	for seed := int64(1); seed <= 300; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		sent := causalRun(rng, 1+rng.Intn(5), 1+rng.Intn(40))
+
+		arrivals := append([]*gRPC.ChatMessage(nil), sent...)
+		rng.Shuffle(len(arrivals), func(i, j int) { arrivals[i], arrivals[j] = arrivals[j], arrivals[i] })
+		// some arrive twice, like broadcasts the server resends
+		for i := rng.Intn(3); i > 0; i-- {
+			arrivals = append(arrivals, sent[rng.Intn(len(sent))])
+		}
+
+		delivered := deliverShuffled(arrivals)
+		if len(delivered) != len(sent) {
+			t.Fatalf("seed %d: delivered %d of %d messages", seed, len(delivered), len(sent))
+		}
+		checkCausalOrder(t, delivered)
+	}
+}
+
+// A message that arrives before the one it answers is held back until that one is in.
+func TestHoldBackWaitsForDependency(t *testing.T) {
+	question := &gRPC.ChatMessage{ClientID: 1, ClientName: "alice", VectorClock: map[int32]int32{1: 1}}
+	answer := &gRPC.ChatMessage{ClientID: 2, ClientName: "bob", VectorClock: map[int32]int32{1: 1, 2: 1}}
+
+	var delivered []*gRPC.ChatMessage
+	h := newHoldBack(&gRPC.RoomState{}, gRPC.ClockMode_VECTOR, 3, time.Minute, func(msg *gRPC.ChatMessage, _ string) {
+		delivered = append(delivered, msg)
+	})
+	h.receive(answer)
+	if len(delivered) != 0 {
+		t.Fatalf("the answer was shown before the question")
+	}
+	h.receive(question)
+	if len(delivered) != 2 || delivered[0] != question || delivered[1] != answer {
+		t.Fatalf("delivered %v, want the question and then the answer", delivered)
+	}
+}
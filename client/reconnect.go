@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var reconnectMin = flag.Duration("reconnect", 500*time.Millisecond, "How long to wait before the first attempt to reconnect, doubled after every failed attempt")
+var reconnectMax = flag.Duration("reconnect-max", 30*time.Second, "The longest wait between attempts to reconnect")
+
+// reconnect keeps trying to resume our session after the stream broke, backing off exponentially.
+// It gives up only when the server won't let us back in at all, like when our name was taken.
+func reconnect(cause error) {
+	chatlog.Event(chatlog.Connection).Err(cause).Warnf("Lost the connection to the server: %v", status.Convert(cause).Message())
+
+	delay := *reconnectMin
+	for attempt := 1; ; attempt++ {
+		// the jitter keeps clients from all coming back at the same moment after a restart
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay)+1))
+		chatlog.Event(chatlog.Connection).Attr(slog.Int("attempt", attempt)).Infof("Reconnecting in %v (attempt %d)", wait.Round(time.Millisecond), attempt)
+		time.Sleep(wait)
+
+		err := resume()
+		if err == nil {
+			chatlog.Event(chatlog.Connect).ClientID(ownID()).Infof("Reconnected as participant %d", ownID())
+			return
+		}
+		switch status.Code(err) {
+		case codes.AlreadyExists, codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated:
+			chatlog.Event(chatlog.Connection).Err(err).Fatalf("Can't rejoin chitty-chat: %v", status.Convert(err).Message())
+		}
+		chatlog.Event(chatlog.Connection).Err(err).Warnf("Failed to reconnect: %v", status.Convert(err).Message())
+		delay = min(delay*2, *reconnectMax)
+	}
+}
+
+// waitForRestart exits when the server is not coming back, and otherwise waits until it should be back.
+func waitForRestart(notice *gRPC.Shutdown) {
+	if notice.RestartIn <= 0 {
+		chatlog.Event(chatlog.Shutdown).Infof("The server has shut down, goodbye")
+		os.Exit(0)
+	}
+	wait := time.Duration(notice.RestartIn) * time.Second
+	chatlog.Event(chatlog.Shutdown).Infof("Waiting %v for the server to come back", wait)
+	time.Sleep(wait)
+}
+
+// resume rejoins as the same participant in the same rooms, and opens a new stream.
+func resume() error {
+	positions := positions()
+	last := make(map[string]int64)
+	for _, position := range positions {
+		last[position.Room] = position.LastSequence
+	}
+
+	sendMutex.Lock()
+	request := &gRPC.ResumeRequest{ClientName: *clientsName, ClientID: clientID, SessionToken: sessionToken, Rooms: positions}
+	sendMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := chatServer.ResumeSession(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	sendMutex.Lock()
+	clientID = reply.ClientID
+	sessionToken = reply.SessionToken
+	if reply.MaxLength > 0 {
+		maxLength = reply.MaxLength
+	}
+	sendMutex.Unlock()
+
+	// if this fails the next attempt ends the session we just got
+	stream, err := chatServer.MessageStream(sessionContext())
+	if err != nil {
+		return err
+	}
+	sendMutex.Lock()
+	chatStream = stream
+	sendMutex.Unlock()
+
+	resumeRooms(reply.Rooms, last)
+	return nil
+}
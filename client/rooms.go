@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// roomView is what the client keeps for a room it is in. Clocks and sequence numbers are per room,
+// so every room has its own sequencer and hold-back queue.
+type roomView struct {
+	name          string
+	delivery      *holdBack  // holds our vector clock of the room and delivers its messages in causal order
+	ordering      *sequencer // puts the rooms broadcasts in the servers order before they reach delivery
+	historyBefore int64      // the oldest broadcast of the room shown so far, /history pages back from here
+}
+
+var roomsMutex sync.Mutex
+var rooms = make(map[string]*roomView)           // room name -> the room, for every room we are in
+var early = make(map[string][]*gRPC.ChatMessage) // messages of rooms we have joined but not set up yet
+var joining = make(map[string]bool)              // rooms we have asked to join, their early messages are kept
+var currentRoom string                           // the room typed messages go to
+
+// maxEarly is how many early messages are kept per room. Anything after that is dropped,
+// the sequencer asks the server to resend it once the room is set up.
+const maxEarly = 256
+
+// addRoom sets up a room we have just joined and makes it the current room.
+// The latest messages of the room are shown, so we know what was going on.
+func addRoom(state *gRPC.RoomState) {
+	name := state.Room
+	view := newRoomView(state)
+
+	if len(state.Backlog) > 0 {
+		fmt.Printf("--- recent messages in %s ---\n", name)
+		printHistory(view, state.Backlog)
+		fmt.Println("--------------------")
+	}
+
+	// the early messages go first, the listener waits for the lock meanwhile
+	roomsMutex.Lock()
+	defer roomsMutex.Unlock()
+	rooms[name] = view
+	currentRoom = name
+	for _, msg := range early[name] {
+		view.ordering.receive(msg)
+	}
+	delete(early, name)
+	delete(joining, name)
+}
+
+// newRoomView starts following a room from the state we got when joining it.
+func newRoomView(state *gRPC.RoomState) *roomView {
+	name := state.Room
+	view := &roomView{
+		name:          name,
+		delivery:      newHoldBack(state, clockMode, ownID(), *holdTimeout, printMessage),
+		historyBefore: state.Sequence + 1,
+	}
+	// missed broadcasts are asked for on our stream
+	view.ordering = newSequencer(state.Sequence, view.delivery.receive, func(from int64, to int64) {
+		sendOnStream(&gRPC.ChatMessage{ClientName: *clientsName, Room: name, Payload: &gRPC.ChatMessage_Resend{Resend: &gRPC.Resend{FromSequence: from, ToSequence: to}}})
+	})
+	return view
+}
+
+// resumeRooms follows our rooms again after reconnecting, and shows what we missed in each of them.
+// last is the last broadcast we had seen in every room before the connection was lost.
+func resumeRooms(states []*gRPC.RoomState, last map[string]int64) {
+	roomsMutex.Lock()
+	defer roomsMutex.Unlock()
+
+	previous := currentRoom
+	rooms = make(map[string]*roomView)
+	early = make(map[string][]*gRPC.ChatMessage)
+	joining = make(map[string]bool)
+	currentRoom = ""
+	for _, state := range states {
+		view := newRoomView(state)
+		rooms[state.Room] = view
+		if state.Room == previous || currentRoom == "" {
+			currentRoom = state.Room
+		}
+
+		if state.Sequence < last[state.Room] {
+			chatlog.Event(chatlog.Ordering).Room(state.Room).Warnf("The server has restarted without the history of %s, messages sent while we were away may be missing", state.Room)
+		} else if len(state.Backlog) > 0 && state.Backlog[0].Sequence > last[state.Room]+1 {
+			chatlog.Event(chatlog.Ordering).Room(state.Room).Warnf("Broadcasts %d to %d of %s are lost, the server no longer has them", last[state.Room]+1, state.Backlog[0].Sequence-1, state.Room)
+		}
+		if len(state.Backlog) > 0 {
+			fmt.Printf("--- missed in %s ---\n", state.Room)
+			printHistory(view, state.Backlog)
+			fmt.Println("--------------------")
+		}
+	}
+}
+
+// positions returns how far we got in each room, to resume from there.
+func positions() []*gRPC.RoomPosition {
+	roomsMutex.Lock()
+	defer roomsMutex.Unlock()
+
+	var positions []*gRPC.RoomPosition
+	for name, view := range rooms {
+		positions = append(positions, &gRPC.RoomPosition{Room: name, LastSequence: view.ordering.last()})
+	}
+	return positions
+}
+
+// receive passes a received message on to the room it belongs to.
+// Broadcasts of a room can arrive before the JoinRoom answer, those wait until the room is set up.
+// Broadcasts of a room we are not joining, like the last ones of a room we just left, are dropped.
+func receive(msg *gRPC.ChatMessage) {
+	// direct messages, rejections, announcements and shutdown notices are not broadcast in the room, they are shown as they arrive
+	switch payload := msg.Payload.(type) {
+	case *gRPC.ChatMessage_Direct, *gRPC.ChatMessage_Rejected, *gRPC.ChatMessage_Announcement:
+		printMessage(msg, "")
+		return
+	case *gRPC.ChatMessage_Shutdown:
+		sendMutex.Lock()
+		shutdown = payload.Shutdown
+		sendMutex.Unlock()
+		printMessage(msg, "")
+		return
+	}
+
+	roomsMutex.Lock()
+	view, ok := rooms[msg.Room]
+	if !ok {
+		if joining[msg.Room] && len(early[msg.Room]) < maxEarly {
+			early[msg.Room] = append(early[msg.Room], msg)
+		}
+		roomsMutex.Unlock()
+		return
+	}
+	roomsMutex.Unlock()
+
+	view.ordering.receive(msg)
+}
+
+// currentView returns the current room, nil if we are in none.
+func currentView() *roomView {
+	roomsMutex.Lock()
+	defer roomsMutex.Unlock()
+	return rooms[currentRoom]
+}
+
+// joinRoom joins the room, or switches to it if we are in it already.
+func joinRoom(name string) {
+	if !strings.HasPrefix(name, "#") {
+		name = "#" + name
+	}
+
+	roomsMutex.Lock()
+	joining[name] = true
+	roomsMutex.Unlock()
+
+	state, err := chatServer.JoinRoom(sessionContext(), &gRPC.RoomRequest{Room: name})
+	if err != nil {
+		// there is no room to set up, so nothing to keep early messages for
+		roomsMutex.Lock()
+		delete(joining, name)
+		delete(early, name)
+		roomsMutex.Unlock()
+	}
+	if status.Code(err) == codes.AlreadyExists {
+		roomsMutex.Lock()
+		currentRoom = name
+		roomsMutex.Unlock()
+		fmt.Printf("Switched to %s \n", name)
+		return
+	}
+	if err != nil {
+		chatlog.Event(chatlog.Join).Room(name).Err(err).Warnf("Failed to join %s: %v", name, err)
+		return
+	}
+	addRoom(state)
+
+	chatlog.Event(chatlog.Join).Room(name).Infof("Joined %s", name)
+}
+
+// leaveRoom leaves the room, messages then go to one of the rooms we are still in.
+func leaveRoom(name string) {
+	if !strings.HasPrefix(name, "#") {
+		name = "#" + name
+	}
+
+	if _, err := chatServer.LeaveRoom(sessionContext(), &gRPC.RoomRequest{Room: name}); err != nil {
+		chatlog.Event(chatlog.Leave).Room(name).Err(err).Warnf("Failed to leave %s: %v", name, err)
+		return
+	}
+
+	roomsMutex.Lock()
+	delete(rooms, name)
+	delete(early, name)
+	if currentRoom == name {
+		currentRoom = ""
+		for other := range rooms {
+			currentRoom = other
+			break
+		}
+	}
+	current := currentRoom
+	roomsMutex.Unlock()
+
+	chatlog.Event(chatlog.Leave).Room(name).Infof("Left %s", name)
+	if current == "" {
+		fmt.Println("You are not in any room, use /join #room")
+	} else {
+		fmt.Printf("Messages now go to %s \n", current)
+	}
+}
+
+// listRooms shows every room on the server and who is in it.
+func listRooms() {
+	list, err := chatServer.ListRooms(sessionContext(), &gRPC.ListRoomsRequest{})
+	if err != nil {
+		chatlog.Event(chatlog.Presence).Err(err).Warnf("Failed to list the rooms: %v", err)
+		return
+	}
+
+	roomsMutex.Lock()
+	defer roomsMutex.Unlock()
+	for _, info := range list.Rooms {
+		marker := " "
+		if info.Room == currentRoom {
+			marker = "*"
+		}
+		fmt.Printf("%s %s: %s \n", marker, info.Room, strings.Join(info.Participants, ", "))
+	}
+}
+
+// watchRooms checks for messages that are held back too long until the program ends.
+func watchRooms() {
+	interval := *holdTimeout / 2
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for now := range time.Tick(interval) {
+		roomsMutex.Lock()
+		for _, view := range rooms {
+			view.delivery.warnHeld(now)
+		}
+		roomsMutex.Unlock()
+	}
+}
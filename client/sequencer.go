@@ -0,0 +1,94 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+)
+
+// sequencer passes broadcasts on strictly in the servers sequence order, so every client shows
+// the same transcript. When a gap shows up it asks the server to resend what is missing.
+type sequencer struct {
+	mutex     sync.Mutex
+	next      int64                       // the sequence number we are waiting for
+	requested int64                       // everything up to here has been asked for already
+	buffered  map[int64]*gRPC.ChatMessage // broadcasts that arrived ahead of next
+	deliver   func(msg *gRPC.ChatMessage) // called in sequence order
+	resend    func(from int64, to int64)  // asks the server for the broadcasts from..to
+}
+
+// newSequencer starts after the last broadcast before we joined.
+func newSequencer(last int64, deliver func(*gRPC.ChatMessage), resend func(int64, int64)) *sequencer {
+	return &sequencer{
+		next:      last + 1,
+		requested: last,
+		buffered:  make(map[int64]*gRPC.ChatMessage),
+		deliver:   deliver,
+		resend:    resend,
+	}
+}
+
+// receive delivers the message if it is next in line, followed by any buffered ones it unblocks.
+// Messages without a sequence number are not broadcasts and are delivered at once.
+func (q *sequencer) receive(msg *gRPC.ChatMessage) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if lost, ok := msg.Payload.(*gRPC.ChatMessage_Resend); ok {
+		q.skip(lost.Resend.FromSequence, lost.Resend.ToSequence)
+		return
+	}
+	if msg.Sequence == 0 {
+		q.deliver(msg)
+		return
+	}
+	if msg.Sequence < q.next {
+		// a resent broadcast we already have
+		return
+	}
+
+	q.buffered[msg.Sequence] = msg
+	if msg.Sequence > q.next && msg.Sequence-1 > q.requested {
+		from := max(q.next, q.requested+1)
+		q.requested = msg.Sequence - 1
+		chatlog.Event(chatlog.Ordering).Warnf("Missed broadcasts %d to %d, asking the server to resend them", from, msg.Sequence-1)
+		q.resend(from, msg.Sequence-1)
+	}
+	q.flush()
+}
+
+// last returns the sequence number of the last broadcast passed on.
+func (q *sequencer) last() int64 {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.next - 1
+}
+
+// skip gives up on broadcasts the server can no longer resend.
+// The caller must hold q.mutex.
+func (q *sequencer) skip(from int64, to int64) {
+	if to < q.next {
+		return
+	}
+	chatlog.Event(chatlog.Ordering).Warnf("Broadcasts %d to %d are lost, the server no longer has them", from, to)
+	for seq := q.next; seq <= to; seq++ {
+		delete(q.buffered, seq)
+	}
+	q.next = to + 1
+	q.flush()
+}
+
+// flush delivers buffered broadcasts for as long as the next one is there.
+// The caller must hold q.mutex.
+func (q *sequencer) flush() {
+	for {
+		msg, ok := q.buffered[q.next]
+		if !ok {
+			return
+		}
+		delete(q.buffered, q.next)
+		q.next++
+		q.deliver(msg)
+	}
+}
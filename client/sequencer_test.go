@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+)
+
+// transcript feeds the broadcasts to a sequencer in a random order, with some lost on the way and resent
+// when the sequencer asks for them, and returns what the holdBack behind it showed.
+func transcript(rng *rand.Rand, mode gRPC.ClockMode, broadcasts []*gRPC.ChatMessage) []string {
+	var shown []string
+	var resent []*gRPC.ChatMessage
+	h := newHoldBack(&gRPC.RoomState{}, mode, 1000, time.Minute, func(msg *gRPC.ChatMessage, _ string) {
+		shown = append(shown, fmt.Sprintf("%d %s", msg.Sequence, msg.GetText().GetContent()))
+	})
+	q := newSequencer(0, h.receive, func(from int64, to int64) {
+		resent = append(resent, broadcasts[from-1:to]...)
+	})
+
+	arrivals := append([]*gRPC.ChatMessage(nil), broadcasts...)
+	rng.Shuffle(len(arrivals), func(i, j int) { arrivals[i], arrivals[j] = arrivals[j], arrivals[i] })
+	for _, msg := range arrivals {
+		if rng.Intn(5) == 0 {
+			continue // lost, it has to come back as a resend
+		}
+		q.receive(msg)
+	}
+	// anything lost at the very end is only noticed with the next broadcast, which the server then resends too
+	for len(resent) > 0 || q.last() < int64(len(broadcasts)) {
+		if len(resent) == 0 {
+			resent = append(resent, broadcasts[q.last()])
+		}
+		msg := resent[0]
+		resent = resent[1:]
+		q.receive(msg)
+	}
+	return shown
+}
+
+// Whatever order the broadcasts arrive in, every client passes them on in the servers order.
+func TestSequencerTranscriptsMatch(t *testing.T) {
+	var broadcasts []*gRPC.ChatMessage
+	for seq := int64(1); seq <= 60; seq++ {
+		broadcasts = append(broadcasts, &gRPC.ChatMessage{Sequence: seq, Payload: &gRPC.ChatMessage_Text{Text: &gRPC.Text{Content: fmt.Sprint("message ", seq)}}})
+	}
+
+	want := transcript(rand.New(rand.NewSource(0)), gRPC.ClockMode_VECTOR, broadcasts)
+	if len(want) != len(broadcasts) {
+		t.Fatalf("passed on %d of %d broadcasts", len(want), len(broadcasts))
+	}
+	for seed := int64(1); seed <= 50; seed++ {
+		got := transcript(rand.New(rand.NewSource(seed)), gRPC.ClockMode_VECTOR, broadcasts)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("seed %d: transcript differs\n got %v\nwant %v", seed, got, want)
+		}
+	}
+}
+
+// With Lamport clocks the stamps of concurrent senders don't have to agree with the servers order.
+// Every client still shows the broadcasts in sequence order, and not sorted by stamp.
+func TestLamportTranscriptsMatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	var broadcasts []*gRPC.ChatMessage
+	var want []string
+	for seq := int64(1); seq <= 60; seq++ {
+		content := fmt.Sprint("message ", seq)
+		broadcasts = append(broadcasts, &gRPC.ChatMessage{
+			Sequence:         seq,
+			ClientID:         1 + rng.Int31n(4),
+			LamportTimestamp: 1 + rng.Int31n(30),
+			Payload:          &gRPC.ChatMessage_Text{Text: &gRPC.Text{Content: content}},
+		})
+		want = append(want, fmt.Sprintf("%d %s", seq, content))
+	}
+
+	for seed := int64(1); seed <= 50; seed++ {
+		got := transcript(rand.New(rand.NewSource(seed)), gRPC.ClockMode_LAMPORT, broadcasts)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("seed %d: transcript differs\n got %v\nwant %v", seed, got, want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// chittycerts makes a self-signed CA with a server certificate and client certificates, for trying out
+// TLS and mutual TLS on a local machine. Don't use them for a real deployment.
+//
+//	go run ./cmd/chittycerts -clients alice,bob
+//	go run ./server -tls-cert certs/server.pem -tls-key certs/server-key.pem -tls-ca certs/ca.pem
+//	go run ./client -name alice -tls-ca certs/ca.pem -tls-cert certs/alice.pem -tls-key certs/alice-key.pem
+//
+// An existing CA in the folder is used again, so more clients can be added later.
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/content"
+)
+
+var out = flag.String("out", "certs", "Folder to write the certificates and keys to")
+var hosts = flag.String("hosts", "localhost,127.0.0.1,::1", "Comma separated host names and IPs the server certificate is valid for")
+var clients = flag.String("clients", "", "Comma separated participant names to make client certificates for, the name goes in the CN")
+var validFor = flag.Duration("valid", 365*24*time.Hour, "How long the certificates are valid")
+
+func main() {
+	flag.Parse()
+
+	if err := run(); err != nil {
+		fmt.Printf("chittycerts: %v \n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	// check every name before anything is written, so a bad one doesn't leave half the certificates made
+	var names []string
+	for _, name := range strings.Split(*clients, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if err := checkClientName(name); err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+
+	ca, caKey, err := loadCA()
+	if errors.Is(err, os.ErrNotExist) {
+		ca, caKey, err = makeCA()
+	}
+	if err != nil {
+		return err
+	}
+
+	server := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "chitty-chat server"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range strings.Split(*hosts, ",") {
+		if host = strings.TrimSpace(host); host == "" {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			server.IPAddresses = append(server.IPAddresses, ip)
+		} else {
+			server.DNSNames = append(server.DNSNames, host)
+		}
+	}
+	if err := issue(server, "server", ca, caKey); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		client := &x509.Certificate{
+			Subject:     pkix.Name{CommonName: name},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		if err := issue(client, name, ca, caKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkClientName tells whether a client certificate can be made for the name. The name is used for the
+// file names, so it must not be a path or one of the names the CA and the server are written under, nor
+// end in -key like the file of another key. It must also be a name the server lets a participant join under.
+func checkClientName(name string) error {
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return fmt.Errorf("the client name %q must not contain / \\ or ..", name)
+	}
+	for _, reserved := range []string{"ca", "server"} {
+		if strings.EqualFold(name, reserved) {
+			return fmt.Errorf("the client name %q is used for the %s certificate", name, reserved)
+		}
+	}
+	if strings.HasSuffix(strings.ToLower(name), "-key") {
+		return fmt.Errorf("the client name %q must not end in -key, that is where the keys are written", name)
+	}
+	if err := content.CheckName(name); err != nil {
+		return fmt.Errorf("the client name %q: %v", name, err)
+	}
+	return nil
+}
+
+// loadCA reads back the CA made by an earlier run.
+func loadCA() (*x509.Certificate, crypto.Signer, error) {
+	certPEM, err := os.ReadFile(filepath.Join(*out, "ca.pem"))
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(*out, "ca-key.pem"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, nil, fmt.Errorf("the CA in %s is not PEM encoded", *out)
+	}
+	ca, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Printf("Using the CA in %s \n", *out)
+	return ca, key, nil
+}
+
+// makeCA makes a new self-signed CA and writes it to ca.pem and ca-key.pem.
+func makeCA() (*x509.Certificate, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{CommonName: "chitty-chat development CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(*validFor),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := write("ca", der, key); err != nil {
+		return nil, nil, err
+	}
+	return ca, key, nil
+}
+
+// issue signs the certificate with the CA and writes it to <name>.pem and <name>-key.pem.
+func issue(template *x509.Certificate, name string, ca *x509.Certificate, caKey crypto.Signer) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template.SerialNumber = serialNumber()
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(*validFor)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, key.Public(), caKey)
+	if err != nil {
+		return err
+	}
+	return write(name, der, key)
+}
+
+// write saves the certificate and its key as PEM files, the key only readable by the owner.
+func write(name string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	certPath := filepath.Join(*out, name+".pem")
+	keyPath := filepath.Join(*out, name+"-key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s and %s \n", certPath, keyPath)
+	return nil
+}
+
+// serialNumber returns a random 128 bit serial number.
+func serialNumber() *big.Int {
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestCheckClientName(t *testing.T) {
+	for _, name := range []string{"ca", "CA", "server", "Server", "alice-key", "ca-key", "../alice", "certs/alice", `..\alice`, "a..b", "/etc/passwd", "bob smith"} {
+		if err := checkClientName(name); err == nil {
+			t.Errorf("%q was allowed", name)
+		}
+	}
+	for _, name := range []string{"alice", "bob", "Bjørn", "client-7", "keyholder", "cat"} {
+		if err := checkClientName(name); err != nil {
+			t.Errorf("%q: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// chittyctl talks to the Admin service of a chitty-chat server, so operators can script moderation
+// and diagnostics instead of reading log_server.txt by hand. The server needs an -admin-token.
+//
+//	go run ./server -admin-token admin.txt
+//	go run ./cmd/chittyctl -admin-token admin.txt list
+//	go run ./cmd/chittyctl -admin-token admin.txt ban -for 1h -reason spam alice
+//	go run ./cmd/chittyctl -admin-token admin.txt tail -room '#dev' | jq .
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/dial"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"github.com/JonasSkjodt/chitty-chat/vclock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var serverAddr = flag.String("server", "5400", "Server address as host:port, a port alone means this machine")
+var adminToken = flag.String("admin-token", "", "File with the admin token of the server")
+var jsonOutput = flag.Bool("json", false, "Print list and clocks as JSON instead of a table")
+var timeout = flag.Duration("timeout", 5*time.Second, "How long a call may take, tail runs until it is stopped")
+var tlsConfig = dial.TLSFlags()
+
+// command is a subcommand, run with the arguments after its name.
+type command struct {
+	usage string
+	run   func(ctx context.Context, admin gRPC.AdminClient, args []string) error
+}
+
+var commands = map[string]command{
+	"list":     {"list", list},
+	"kick":     {"kick [-reason text] <name>", kick},
+	"ban":      {"ban [-ip IP] [-for duration] [-reason text] [name]", ban},
+	"unban":    {"unban [-ip IP] [name]", unban},
+	"mute":     {"mute [-for duration] [-reason text] <name>, -for 0 lifts the mute", mute},
+	"announce": {"announce [-room #room] <text>", announce},
+	"clocks":   {"clocks", clocks},
+	"tail":     {"tail [-room #room], every broadcast as a line of JSON until Ctrl+C", tailMessages},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "chittyctl: unknown command %q \n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	if err := run(cmd, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "chittyctl: %v \n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: chittyctl [flags] <command>\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// run dials the server and runs the command with the admin token on every call.
+func run(cmd command, args []string) error {
+	if *adminToken == "" {
+		return errors.New("-admin-token is needed, the file with the admin token of the server")
+	}
+	token, err := os.ReadFile(*adminToken)
+	if err != nil {
+		return err
+	}
+	creds, err := tlsConfig.TransportCredentials()
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(dial.Target(*serverAddr), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Ctrl+C stops a tail, and any other call that hangs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+strings.TrimSpace(string(token)))
+	return cmd.run(ctx, gRPC.NewAdminClient(conn), args)
+}
+
+// call gives a unary call the -timeout.
+func call(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, *timeout)
+}
+
+func list(ctx context.Context, admin gRPC.AdminClient, args []string) error {
+	ctx, cancel := call(ctx)
+	defer cancel()
+	reply, err := admin.ListParticipants(ctx, &gRPC.ListParticipantsRequest{})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		return printJSON(reply)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID\tADDRESS\tCONNECTED\tMUTED\tCLOCK SLOTS")
+	for _, p := range reply.Participants {
+		muted := "-"
+		if p.MutedFor > 0 {
+			muted = fmt.Sprintf("%ds", p.MutedFor)
+		}
+		rooms := make([]string, 0, len(p.ClockSlots))
+		for room, slot := range p.ClockSlots {
+			rooms = append(rooms, fmt.Sprintf("%s:%d", room, slot))
+		}
+		sort.Strings(rooms)
+		connected := time.Unix(p.ConnectedAt, 0).Format(time.DateTime)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", p.ClientName, p.ClientID, p.Address, connected, muted, strings.Join(rooms, " "))
+	}
+	return w.Flush()
+}
+
+func kick(ctx context.Context, admin gRPC.AdminClient, args []string) error {
+	flags := flag.NewFlagSet("kick", flag.ExitOnError)
+	reason := flags.String("reason", "", "Told to the participant and the rooms it was in")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("kick needs the name of a participant")
+	}
+
+	ctx, cancel := call(ctx)
+	defer cancel()
+	return printAck(admin.Kick(ctx, &gRPC.KickRequest{ClientName: flags.Arg(0), Reason: *reason}))
+}
+
+func ban(ctx context.Context, admin gRPC.AdminClient, args []string) error {
+	flags := flag.NewFlagSet("ban", flag.ExitOnError)
+	ip := flags.String("ip", "", "IP to ban, everyone connected from it is disconnected")
+	duration := flags.Duration("for", 0, "How long the ban lasts, 0 until the server restarts")
+	reason := flags.String("reason", "", "Told to whoever is banned")
+	flags.Parse(args)
+	if flags.NArg() > 1 || (flags.NArg() == 0 && *ip == "") {
+		return errors.New("ban needs the name of a participant, an -ip or both")
+	}
+
+	ctx, cancel := call(ctx)
+	defer cancel()
+	return printAck(admin.Ban(ctx, &gRPC.BanRequest{ClientName: flags.Arg(0), Ip: *ip, Duration: seconds(*duration), Reason: *reason}))
+}
+
+func unban(ctx context.Context, admin gRPC.AdminClient, args []string) error {
+	flags := flag.NewFlagSet("unban", flag.ExitOnError)
+	ip := flags.String("ip", "", "IP to let back in")
+	flags.Parse(args)
+	if flags.NArg() > 1 || (flags.NArg() == 0 && *ip == "") {
+		return errors.New("unban needs the name of a participant, an -ip or both")
+	}
+
+	ctx, cancel := call(ctx)
+	defer cancel()
+	return printAck(admin.Unban(ctx, &gRPC.BanRequest{ClientName: flags.Arg(0), Ip: *ip}))
+}
+
+func mute(ctx context.Context, admin gRPC.AdminClient, args []string) error {
+	flags := flag.NewFlagSet("mute", flag.ExitOnError)
+	duration := flags.Duration("for", 5*time.Minute, "How long the participant may not send, 0 lifts the mute")
+	reason := flags.String("reason", "", "Told to the participant when it tries to send")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("mute needs the name of a participant")
+	}
+
+	ctx, cancel := call(ctx)
+	defer cancel()
+	return printAck(admin.Mute(ctx, &gRPC.MuteRequest{ClientName: flags.Arg(0), Duration: seconds(*duration), Reason: *reason}))
+}
+
+func announce(ctx context.Context, admin gRPC.AdminClient, args []string) error {
+	flags := flag.NewFlagSet("announce", flag.ExitOnError)
+	room := flags.String("room", "", "Room to announce in, every participant gets it when empty")
+	flags.Parse(args)
+	text := strings.Join(flags.Args(), " ")
+	if text == "" {
+		return errors.New("announce needs the text of the announcement")
+	}
+
+	ctx, cancel := call(ctx)
+	defer cancel()
+	return printAck(admin.Broadcast(ctx, &gRPC.Announcement{Content: text, Room: *room}))
+}
+
+func clocks(ctx context.Context, admin gRPC.AdminClient, args []string) error {
+	ctx, cancel := call(ctx)
+	defer cancel()
+	reply, err := admin.Clocks(ctx, &gRPC.ClocksRequest{})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		return printJSON(reply)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ROOM\tSEQUENCE\tCLOCK")
+	for _, r := range reply.Rooms {
+		clock := vclock.Clock(r.VectorClock).String()
+		if reply.ClockMode == gRPC.ClockMode_LAMPORT {
+			clock = fmt.Sprintf("lamport %d", r.LamportTimestamp)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", r.Room, r.Sequence, clock)
+	}
+	return w.Flush()
+}
+
+// tailMessages prints every broadcast as a line of JSON, until Ctrl+C or the server stops.
+func tailMessages(ctx context.Context, admin gRPC.AdminClient, args []string) error {
+	flags := flag.NewFlagSet("tail", flag.ExitOnError)
+	room := flags.String("room", "", "Room to follow, every room when empty")
+	flags.Parse(args)
+
+	stream, err := admin.Tail(ctx, &gRPC.TailRequest{Room: *room})
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, err := protojson.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(line))
+	}
+}
+
+func printAck(ack *gRPC.Ack, err error) error {
+	if err != nil {
+		return err
+	}
+	fmt.Println(ack.Message)
+	return nil
+}
+
+func printJSON(m proto.Message) error {
+	out, err := protojson.MarshalOptions{Multiline: true}.Marshal(m)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// seconds rounds a duration up to whole seconds, the Admin service counts in seconds.
+func seconds(d time.Duration) int32 {
+	return int32(math.Ceil(d.Seconds()))
+}
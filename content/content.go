@@ -0,0 +1,154 @@
+// Package content checks what participants type before it is shown to anyone.
+//
+// The server cleans every Text and Direct with it, so a client that doesn't go through our input
+// can't mess with the terminals of everyone else. Clients run the same check before they send,
+// as a message the server rejects would leave a gap in the clock of the sender.
+package content
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Clean checks the content of a Text or Direct and returns it the way it is broadcast.
+// Terminal escape sequences and other control characters are stripped. Content that is not valid
+// UTF-8, empty after stripping or longer than maxLength characters (runes, not bytes) is rejected.
+func Clean(content string, maxLength int) (string, *gRPC.Rejected) {
+	if !utf8.ValidString(content) {
+		return "", &gRPC.Rejected{Reason: gRPC.RejectReason_INVALID_UTF8, Detail: "the message is not valid UTF-8"}
+	}
+	content = strings.TrimSpace(stripControls(content))
+	if content == "" {
+		return "", &gRPC.Rejected{Reason: gRPC.RejectReason_EMPTY, Detail: "the message is empty"}
+	}
+	if length := utf8.RuneCountInString(content); length > maxLength {
+		return "", &gRPC.Rejected{
+			Reason:    gRPC.RejectReason_TOO_LONG,
+			Detail:    fmt.Sprintf("the message has %d characters, at most %d are allowed", length, maxLength),
+			MaxLength: int32(maxLength),
+		}
+	}
+	return content, nil
+}
+
+// CheckName checks a participant name. Names are shown with every message and every join, so instead of
+// cleaning them like messages, names with spaces, control characters (like terminal escape sequences) or
+// invisible formatting characters (like bidi controls and zero-width spaces) are refused:
+// they could make one name look like another, or like the server.
+func CheckName(name string) error {
+	if !utf8.ValidString(name) {
+		return errors.New("the name is not valid UTF-8")
+	}
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			return errors.New("the name must not contain spaces")
+		case unicode.IsControl(r), bidiControl(r), unicode.Is(unicode.Cf, r):
+			return fmt.Errorf("the name must not contain control or invisible characters like %U", r)
+		}
+	}
+	return nil
+}
+
+// Error turns a rejection into the status SendDirect fails with, the rejection goes in the details.
+func Error(rejected *gRPC.Rejected) error {
+	st, err := status.New(codes.InvalidArgument, rejected.Detail).WithDetails(rejected)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, rejected.Detail)
+	}
+	return st.Err()
+}
+
+// Rejection finds the rejection in the details of an error returned by SendDirect, or nil if it has none.
+func Rejection(err error) *gRPC.Rejected {
+	for _, detail := range status.Convert(err).Details() {
+		if rejected, ok := detail.(*gRPC.Rejected); ok {
+			return rejected
+		}
+	}
+	return nil
+}
+
+// stripControls removes ANSI escape sequences, control characters and the characters that
+// reverse the direction of text. Tabs and line breaks become spaces.
+func stripControls(content string) string {
+	var b strings.Builder
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\x1b' && i+1 < len(runes):
+			i = skipEscape(runes, i+1)
+		case r == '\u009b':
+			// the single character version of ESC [
+			i = skipCSI(runes, i+1)
+		case r == '\u009d' || r == '\u0090' || r == '\u009e' || r == '\u009f' || r == '\u0098':
+			// the single character versions of ESC ] P ^ _ X
+			i = skipString(runes, i+1)
+		case r == '\t' || r == '\n' || r == '\r':
+			b.WriteRune(' ')
+		case unicode.IsControl(r), bidiControl(r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// skipEscape returns the index of the last character of the escape sequence after an ESC at start-1.
+func skipEscape(runes []rune, start int) int {
+	switch runes[start] {
+	case '[':
+		return skipCSI(runes, start+1)
+	case ']', 'P', '^', '_', 'X':
+		return skipString(runes, start+1)
+	}
+	// a two or three character sequence like ESC c or ESC ( B
+	i := start
+	for i < len(runes) && runes[i] >= 0x20 && runes[i] <= 0x2f {
+		i++
+	}
+	if i < len(runes) && runes[i] >= 0x30 && runes[i] <= 0x7e {
+		return i
+	}
+	// cut off, or broken by another control character like a second ESC, which is then looked at on its own
+	return i - 1
+}
+
+// skipCSI skips the parameters of a control sequence up to and including its final character.
+func skipCSI(runes []rune, start int) int {
+	i := start
+	for i < len(runes) && (runes[i] < 0x40 || runes[i] > 0x7e) {
+		if runes[i] < 0x20 || runes[i] > 0x3f {
+			// not a parameter, so the sequence ends here without a final character
+			return i - 1
+		}
+		i++
+	}
+	return i
+}
+
+// skipString skips an operating system command or similar string up to BEL or ESC \.
+func skipString(runes []rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\a' || runes[i] == '\u009c':
+			return i
+		case runes[i] == '\x1b' && i+1 < len(runes) && runes[i+1] == '\\':
+			return i + 1
+		}
+	}
+	return len(runes) - 1
+}
+
+// bidiControl tells whether r changes the direction of the text after it, which can make a message look like something else.
+func bidiControl(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069') || r == '\u200e' || r == '\u200f'
+}
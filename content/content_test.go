@@ -0,0 +1,42 @@
+package content
+
+import "testing"
+
+func TestStripControls(t *testing.T) {
+	for _, tc := range []struct {
+		name, in, want string
+	}{
+		{"plain", "hello", "hello"},
+		{"CSI colour", "\x1b[31mred\x1b[0m", "red"},
+		{"CSI clear screen", "x\x1b[2Jy", "xy"},
+		{"CSI private mode", "\x1b[?25lhidden", "hidden"},
+		{"C1 CSI", "\u009b31mred", "red"},
+		{"OSC ended by BEL", "\x1b]0;title\atext", "text"},
+		{"OSC ended by ESC \\", "\x1b]8;;http://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"C1 OSC ended by ST", "\u009d0;title\u009ctext", "text"},
+		{"DCS", "\x1bPq#0;2;0;0;0\x1b\\after", "after"},
+		{"C1 DCS", "\u0090stuff\u009cafter", "after"},
+		{"APC, PM and SOS", "\x1b_a\x1b\\b\x1b^c\x1b\\d\x1bXe\x1b\\f", "bdf"},
+		{"two character sequence", "a\x1bcb", "ab"},
+		{"charset sequence", "a\x1b(Bb", "ab"},
+		{"truncated CSI", "text\x1b[31", "text"},
+		{"truncated OSC", "text\x1b]0;title", "text"},
+		{"truncated charset sequence", "text\x1b(", "text"},
+		{"ESC at the end", "text\x1b", "text"},
+		{"repeated ESC", "x\x1b\x1b[2Jy", "xy"},
+		{"ESC three times", "x\x1b\x1b\x1b[31my", "xy"},
+		{"CSI broken by ESC", "x\x1b[3\x1b[2Jy", "xy"},
+		{"ESC before a control character", "x\x1b\ay", "xy"},
+		{"C1 controls", "a\u0085b\u0084c", "abc"},
+		{"C0 controls and DEL", "a\x07b\x00c\x7fd", "abcd"},
+		{"tabs and line breaks", "a\tb\nc\rd", "a b c d"},
+		{"right-to-left override", "abc\u202edcba", "abcdcba"},
+		{"bidi isolates", "\u2066x\u2069", "x"},
+		{"bidi marks", "\u200fx\u200e", "x"},
+		{"not a control", "Bjørn 张伟 ✓", "Bjørn 张伟 ✓"},
+	} {
+		if got := stripControls(tc.in); got != tc.want {
+			t.Errorf("%s: stripControls(%q) = %q, want %q", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package dial
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestTarget(t *testing.T) {
+	for server, want := range map[string]string{
+		"5400":                  "localhost:5400",
+		":5400":                 "localhost:5400",
+		"localhost:5400":        "localhost:5400",
+		"127.0.0.2:5400":        "127.0.0.2:5400",
+		"192.168.1.20:5400":     "192.168.1.20:5400",
+		"[::1]:5400":            "[::1]:5400",
+		"chat.example.com:5400": "chat.example.com:5400",
+	} {
+		if got := Target(server); got != want {
+			t.Errorf("Target(%q) = %q, want %q", server, got, want)
+		}
+	}
+}
+
+// check asks the Health service at the target whether it is serving.
+func check(target string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+	return err
+}
+
+// Every address in 127.0.0.0/8 is this machine on Linux, so servers listening on 127.0.0.2 and 127.0.0.3
+// stand in for other machines: the client has to reach each by its full address, and not end up on localhost.
+func TestDialLoopbackAliases(t *testing.T) {
+	for _, alias := range []string{"127.0.0.2", "127.0.0.3"} {
+		list, err := net.Listen("tcp", net.JoinHostPort(alias, "0"))
+		if err != nil {
+			t.Skipf("can't listen on the loopback alias %s: %v", alias, err)
+		}
+		server := grpc.NewServer()
+		healthpb.RegisterHealthServer(server, health.NewServer())
+		go server.Serve(list)
+		defer server.Stop()
+
+		address := list.Addr().String()
+		if err := check(Target(address), 5*time.Second); err != nil {
+			t.Errorf("dialing %s: %v", address, err)
+		}
+		_, port, _ := net.SplitHostPort(address)
+		if err := check(Target(port), time.Second); err == nil {
+			t.Errorf("reached the server on %s at localhost:%s", alias, port)
+		}
+	}
+}
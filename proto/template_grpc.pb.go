@@ -21,7 +21,13 @@ const _ = grpc.SupportPackageIsVersion7
 const (
 	Chat_MessageStream_FullMethodName        = "/proto.Chat/MessageStream"
 	Chat_ConnectToServer_FullMethodName      = "/proto.Chat/ConnectToServer"
+	Chat_ResumeSession_FullMethodName        = "/proto.Chat/ResumeSession"
 	Chat_DisconnectFromServer_FullMethodName = "/proto.Chat/DisconnectFromServer"
+	Chat_History_FullMethodName              = "/proto.Chat/History"
+	Chat_JoinRoom_FullMethodName             = "/proto.Chat/JoinRoom"
+	Chat_LeaveRoom_FullMethodName            = "/proto.Chat/LeaveRoom"
+	Chat_ListRooms_FullMethodName            = "/proto.Chat/ListRooms"
+	Chat_SendDirect_FullMethodName           = "/proto.Chat/SendDirect"
 )
 
 // ChatClient is the client API for Chat service.
@@ -29,8 +35,25 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ChatClient interface {
 	MessageStream(ctx context.Context, opts ...grpc.CallOption) (Chat_MessageStreamClient, error)
-	ConnectToServer(ctx context.Context, opts ...grpc.CallOption) (Chat_ConnectToServerClient, error)
-	DisconnectFromServer(ctx context.Context, in *ClientName, opts ...grpc.CallOption) (*Ack, error)
+	// ConnectToServer registers a participant and hands back the session
+	// the client has to attach to MessageStream (as "session-token" metadata).
+	ConnectToServer(ctx context.Context, in *ClientName, opts ...grpc.CallOption) (*JoinReply, error)
+	// ResumeSession joins again after the connection was lost, under the same name and if possible the same ClientID.
+	// The participant is put back in its rooms and gets what it missed in each of them as the backlog.
+	ResumeSession(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeReply, error)
+	DisconnectFromServer(ctx context.Context, in *Session, opts ...grpc.CallOption) (*Ack, error)
+	// History pages backwards through the kept broadcasts, oldest first within a page.
+	// Needs the same "session-token" metadata as MessageStream.
+	History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (Chat_HistoryClient, error)
+	// JoinRoom, LeaveRoom and ListRooms also need the "session-token" metadata.
+	// Every participant starts out in the room #general.
+	JoinRoom(ctx context.Context, in *RoomRequest, opts ...grpc.CallOption) (*RoomState, error)
+	LeaveRoom(ctx context.Context, in *RoomRequest, opts ...grpc.CallOption) (*Ack, error)
+	ListRooms(ctx context.Context, in *ListRoomsRequest, opts ...grpc.CallOption) (*RoomList, error)
+	// SendDirect sends a private message to one participant, the recipient gets it as a ChatMessage
+	// with a Direct payload and so does the sender. Fails with NOT_FOUND if the recipient is not online.
+	// Needs the "session-token" metadata.
+	SendDirect(ctx context.Context, in *Direct, opts ...grpc.CallOption) (*Ack, error)
 }
 
 type chatClient struct {
@@ -72,43 +95,95 @@ func (x *chatMessageStreamClient) Recv() (*ChatMessage, error) {
 	return m, nil
 }
 
-func (c *chatClient) ConnectToServer(ctx context.Context, opts ...grpc.CallOption) (Chat_ConnectToServerClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Chat_ServiceDesc.Streams[1], Chat_ConnectToServer_FullMethodName, opts...)
+func (c *chatClient) ConnectToServer(ctx context.Context, in *ClientName, opts ...grpc.CallOption) (*JoinReply, error) {
+	out := new(JoinReply)
+	err := c.cc.Invoke(ctx, Chat_ConnectToServer_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &chatConnectToServerClient{stream}
+	return out, nil
+}
+
+func (c *chatClient) ResumeSession(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeReply, error) {
+	out := new(ResumeReply)
+	err := c.cc.Invoke(ctx, Chat_ResumeSession_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) DisconnectFromServer(ctx context.Context, in *Session, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, Chat_DisconnectFromServer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (Chat_HistoryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Chat_ServiceDesc.Streams[1], Chat_History_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatHistoryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
 	return x, nil
 }
 
-type Chat_ConnectToServerClient interface {
-	Send(*ChatMessage) error
-	CloseAndRecv() (*Ack, error)
+type Chat_HistoryClient interface {
+	Recv() (*ChatMessage, error)
 	grpc.ClientStream
 }
 
-type chatConnectToServerClient struct {
+type chatHistoryClient struct {
 	grpc.ClientStream
 }
 
-func (x *chatConnectToServerClient) Send(m *ChatMessage) error {
-	return x.ClientStream.SendMsg(m)
+func (x *chatHistoryClient) Recv() (*ChatMessage, error) {
+	m := new(ChatMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func (x *chatConnectToServerClient) CloseAndRecv() (*Ack, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
+func (c *chatClient) JoinRoom(ctx context.Context, in *RoomRequest, opts ...grpc.CallOption) (*RoomState, error) {
+	out := new(RoomState)
+	err := c.cc.Invoke(ctx, Chat_JoinRoom_FullMethodName, in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	m := new(Ack)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+	return out, nil
+}
+
+func (c *chatClient) LeaveRoom(ctx context.Context, in *RoomRequest, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, Chat_LeaveRoom_FullMethodName, in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
+}
+
+func (c *chatClient) ListRooms(ctx context.Context, in *ListRoomsRequest, opts ...grpc.CallOption) (*RoomList, error) {
+	out := new(RoomList)
+	err := c.cc.Invoke(ctx, Chat_ListRooms_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *chatClient) DisconnectFromServer(ctx context.Context, in *ClientName, opts ...grpc.CallOption) (*Ack, error) {
+func (c *chatClient) SendDirect(ctx context.Context, in *Direct, opts ...grpc.CallOption) (*Ack, error) {
 	out := new(Ack)
-	err := c.cc.Invoke(ctx, Chat_DisconnectFromServer_FullMethodName, in, out, opts...)
+	err := c.cc.Invoke(ctx, Chat_SendDirect_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -120,8 +195,25 @@ func (c *chatClient) DisconnectFromServer(ctx context.Context, in *ClientName, o
 // for forward compatibility
 type ChatServer interface {
 	MessageStream(Chat_MessageStreamServer) error
-	ConnectToServer(Chat_ConnectToServerServer) error
-	DisconnectFromServer(context.Context, *ClientName) (*Ack, error)
+	// ConnectToServer registers a participant and hands back the session
+	// the client has to attach to MessageStream (as "session-token" metadata).
+	ConnectToServer(context.Context, *ClientName) (*JoinReply, error)
+	// ResumeSession joins again after the connection was lost, under the same name and if possible the same ClientID.
+	// The participant is put back in its rooms and gets what it missed in each of them as the backlog.
+	ResumeSession(context.Context, *ResumeRequest) (*ResumeReply, error)
+	DisconnectFromServer(context.Context, *Session) (*Ack, error)
+	// History pages backwards through the kept broadcasts, oldest first within a page.
+	// Needs the same "session-token" metadata as MessageStream.
+	History(*HistoryRequest, Chat_HistoryServer) error
+	// JoinRoom, LeaveRoom and ListRooms also need the "session-token" metadata.
+	// Every participant starts out in the room #general.
+	JoinRoom(context.Context, *RoomRequest) (*RoomState, error)
+	LeaveRoom(context.Context, *RoomRequest) (*Ack, error)
+	ListRooms(context.Context, *ListRoomsRequest) (*RoomList, error)
+	// SendDirect sends a private message to one participant, the recipient gets it as a ChatMessage
+	// with a Direct payload and so does the sender. Fails with NOT_FOUND if the recipient is not online.
+	// Needs the "session-token" metadata.
+	SendDirect(context.Context, *Direct) (*Ack, error)
 	mustEmbedUnimplementedChatServer()
 }
 
@@ -132,12 +224,30 @@ type UnimplementedChatServer struct {
 func (UnimplementedChatServer) MessageStream(Chat_MessageStreamServer) error {
 	return status.Errorf(codes.Unimplemented, "method MessageStream not implemented")
 }
-func (UnimplementedChatServer) ConnectToServer(Chat_ConnectToServerServer) error {
-	return status.Errorf(codes.Unimplemented, "method ConnectToServer not implemented")
+func (UnimplementedChatServer) ConnectToServer(context.Context, *ClientName) (*JoinReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConnectToServer not implemented")
+}
+func (UnimplementedChatServer) ResumeSession(context.Context, *ResumeRequest) (*ResumeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeSession not implemented")
 }
-func (UnimplementedChatServer) DisconnectFromServer(context.Context, *ClientName) (*Ack, error) {
+func (UnimplementedChatServer) DisconnectFromServer(context.Context, *Session) (*Ack, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DisconnectFromServer not implemented")
 }
+func (UnimplementedChatServer) History(*HistoryRequest, Chat_HistoryServer) error {
+	return status.Errorf(codes.Unimplemented, "method History not implemented")
+}
+func (UnimplementedChatServer) JoinRoom(context.Context, *RoomRequest) (*RoomState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinRoom not implemented")
+}
+func (UnimplementedChatServer) LeaveRoom(context.Context, *RoomRequest) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaveRoom not implemented")
+}
+func (UnimplementedChatServer) ListRooms(context.Context, *ListRoomsRequest) (*RoomList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRooms not implemented")
+}
+func (UnimplementedChatServer) SendDirect(context.Context, *Direct) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendDirect not implemented")
+}
 func (UnimplementedChatServer) mustEmbedUnimplementedChatServer() {}
 
 // UnsafeChatServer may be embedded to opt out of forward compatibility for this service.
@@ -177,46 +287,149 @@ func (x *chatMessageStreamServer) Recv() (*ChatMessage, error) {
 	return m, nil
 }
 
-func _Chat_ConnectToServer_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(ChatServer).ConnectToServer(&chatConnectToServerServer{stream})
+func _Chat_ConnectToServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientName)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).ConnectToServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_ConnectToServer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).ConnectToServer(ctx, req.(*ClientName))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type Chat_ConnectToServerServer interface {
-	SendAndClose(*Ack) error
-	Recv() (*ChatMessage, error)
+func _Chat_ResumeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).ResumeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_ResumeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).ResumeSession(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_DisconnectFromServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Session)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).DisconnectFromServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_DisconnectFromServer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).DisconnectFromServer(ctx, req.(*Session))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_History_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(HistoryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServer).History(m, &chatHistoryServer{stream})
+}
+
+type Chat_HistoryServer interface {
+	Send(*ChatMessage) error
 	grpc.ServerStream
 }
 
-type chatConnectToServerServer struct {
+type chatHistoryServer struct {
 	grpc.ServerStream
 }
 
-func (x *chatConnectToServerServer) SendAndClose(m *Ack) error {
+func (x *chatHistoryServer) Send(m *ChatMessage) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func (x *chatConnectToServerServer) Recv() (*ChatMessage, error) {
-	m := new(ChatMessage)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
+func _Chat_JoinRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoomRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	if interceptor == nil {
+		return srv.(ChatServer).JoinRoom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_JoinRoom_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).JoinRoom(ctx, req.(*RoomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Chat_DisconnectFromServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ClientName)
+func _Chat_LeaveRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoomRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServer).DisconnectFromServer(ctx, in)
+		return srv.(ChatServer).LeaveRoom(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Chat_DisconnectFromServer_FullMethodName,
+		FullMethod: Chat_LeaveRoom_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServer).DisconnectFromServer(ctx, req.(*ClientName))
+		return srv.(ChatServer).LeaveRoom(ctx, req.(*RoomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_ListRooms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRoomsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).ListRooms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_ListRooms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).ListRooms(ctx, req.(*ListRoomsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_SendDirect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Direct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).SendDirect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_SendDirect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).SendDirect(ctx, req.(*Direct))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -228,10 +441,34 @@ var Chat_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Chat",
 	HandlerType: (*ChatServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ConnectToServer",
+			Handler:    _Chat_ConnectToServer_Handler,
+		},
+		{
+			MethodName: "ResumeSession",
+			Handler:    _Chat_ResumeSession_Handler,
+		},
 		{
 			MethodName: "DisconnectFromServer",
 			Handler:    _Chat_DisconnectFromServer_Handler,
 		},
+		{
+			MethodName: "JoinRoom",
+			Handler:    _Chat_JoinRoom_Handler,
+		},
+		{
+			MethodName: "LeaveRoom",
+			Handler:    _Chat_LeaveRoom_Handler,
+		},
+		{
+			MethodName: "ListRooms",
+			Handler:    _Chat_ListRooms_Handler,
+		},
+		{
+			MethodName: "SendDirect",
+			Handler:    _Chat_SendDirect_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -241,9 +478,404 @@ var Chat_ServiceDesc = grpc.ServiceDesc{
 			ClientStreams: true,
 		},
 		{
-			StreamName:    "ConnectToServer",
-			Handler:       _Chat_ConnectToServer_Handler,
-			ClientStreams: true,
+			StreamName:    "History",
+			Handler:       _Chat_History_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/template.proto",
+}
+
+const (
+	Admin_ListParticipants_FullMethodName = "/proto.Admin/ListParticipants"
+	Admin_Kick_FullMethodName             = "/proto.Admin/Kick"
+	Admin_Ban_FullMethodName              = "/proto.Admin/Ban"
+	Admin_Unban_FullMethodName            = "/proto.Admin/Unban"
+	Admin_Mute_FullMethodName             = "/proto.Admin/Mute"
+	Admin_Broadcast_FullMethodName        = "/proto.Admin/Broadcast"
+	Admin_Clocks_FullMethodName           = "/proto.Admin/Clocks"
+	Admin_Tail_FullMethodName             = "/proto.Admin/Tail"
+)
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminClient interface {
+	// ListParticipants tells who is connected, ordered by name.
+	ListParticipants(ctx context.Context, in *ListParticipantsRequest, opts ...grpc.CallOption) (*ParticipantList, error)
+	// Kick disconnects a participant, it may join again. Fails with NOT_FOUND if it is not connected.
+	Kick(ctx context.Context, in *KickRequest, opts ...grpc.CallOption) (*Ack, error)
+	// Ban disconnects everyone with the name or from the IP, and keeps them from joining for the duration.
+	Ban(ctx context.Context, in *BanRequest, opts ...grpc.CallOption) (*Ack, error)
+	// Unban lifts the ban of the name, the IP or both. Fails with NOT_FOUND if neither is banned.
+	Unban(ctx context.Context, in *BanRequest, opts ...grpc.CallOption) (*Ack, error)
+	// Mute keeps a participant from sending Text and Direct messages for the duration, reconnecting doesn't help.
+	Mute(ctx context.Context, in *MuteRequest, opts ...grpc.CallOption) (*Ack, error)
+	// Broadcast sends an announcement to every participant, or to the members of one room.
+	Broadcast(ctx context.Context, in *Announcement, opts ...grpc.CallOption) (*Ack, error)
+	// Clocks tells the clocks and the last sequence number of every room.
+	Clocks(ctx context.Context, in *ClocksRequest, opts ...grpc.CallOption) (*ClockList, error)
+	// Tail streams every broadcast and announcement as it is sent, until the call is cancelled or the server stops.
+	// Direct messages are private and never tailed. An operator that can't keep up misses messages.
+	Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (Admin_TailClient, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListParticipants(ctx context.Context, in *ListParticipantsRequest, opts ...grpc.CallOption) (*ParticipantList, error) {
+	out := new(ParticipantList)
+	err := c.cc.Invoke(ctx, Admin_ListParticipants_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Kick(ctx context.Context, in *KickRequest, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, Admin_Kick_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Ban(ctx context.Context, in *BanRequest, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, Admin_Ban_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Unban(ctx context.Context, in *BanRequest, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, Admin_Unban_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Mute(ctx context.Context, in *MuteRequest, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, Admin_Mute_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Broadcast(ctx context.Context, in *Announcement, opts ...grpc.CallOption) (*Ack, error) {
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, Admin_Broadcast_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Clocks(ctx context.Context, in *ClocksRequest, opts ...grpc.CallOption) (*ClockList, error) {
+	out := new(ClockList)
+	err := c.cc.Invoke(ctx, Admin_Clocks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (Admin_TailClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[0], Admin_Tail_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminTailClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_TailClient interface {
+	Recv() (*ChatMessage, error)
+	grpc.ClientStream
+}
+
+type adminTailClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminTailClient) Recv() (*ChatMessage, error) {
+	m := new(ChatMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility
+type AdminServer interface {
+	// ListParticipants tells who is connected, ordered by name.
+	ListParticipants(context.Context, *ListParticipantsRequest) (*ParticipantList, error)
+	// Kick disconnects a participant, it may join again. Fails with NOT_FOUND if it is not connected.
+	Kick(context.Context, *KickRequest) (*Ack, error)
+	// Ban disconnects everyone with the name or from the IP, and keeps them from joining for the duration.
+	Ban(context.Context, *BanRequest) (*Ack, error)
+	// Unban lifts the ban of the name, the IP or both. Fails with NOT_FOUND if neither is banned.
+	Unban(context.Context, *BanRequest) (*Ack, error)
+	// Mute keeps a participant from sending Text and Direct messages for the duration, reconnecting doesn't help.
+	Mute(context.Context, *MuteRequest) (*Ack, error)
+	// Broadcast sends an announcement to every participant, or to the members of one room.
+	Broadcast(context.Context, *Announcement) (*Ack, error)
+	// Clocks tells the clocks and the last sequence number of every room.
+	Clocks(context.Context, *ClocksRequest) (*ClockList, error)
+	// Tail streams every broadcast and announcement as it is sent, until the call is cancelled or the server stops.
+	// Direct messages are private and never tailed. An operator that can't keep up misses messages.
+	Tail(*TailRequest, Admin_TailServer) error
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (UnimplementedAdminServer) ListParticipants(context.Context, *ListParticipantsRequest) (*ParticipantList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListParticipants not implemented")
+}
+func (UnimplementedAdminServer) Kick(context.Context, *KickRequest) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Kick not implemented")
+}
+func (UnimplementedAdminServer) Ban(context.Context, *BanRequest) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ban not implemented")
+}
+func (UnimplementedAdminServer) Unban(context.Context, *BanRequest) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unban not implemented")
+}
+func (UnimplementedAdminServer) Mute(context.Context, *MuteRequest) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Mute not implemented")
+}
+func (UnimplementedAdminServer) Broadcast(context.Context, *Announcement) (*Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Broadcast not implemented")
+}
+func (UnimplementedAdminServer) Clocks(context.Context, *ClocksRequest) (*ClockList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Clocks not implemented")
+}
+func (UnimplementedAdminServer) Tail(*TailRequest, Admin_TailServer) error {
+	return status.Errorf(codes.Unimplemented, "method Tail not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_ListParticipants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListParticipantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListParticipants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListParticipants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListParticipants(ctx, req.(*ListParticipantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Kick_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KickRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Kick(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_Kick_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Kick(ctx, req.(*KickRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Ban_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Ban(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_Ban_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Ban(ctx, req.(*BanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Unban_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Unban(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_Unban_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Unban(ctx, req.(*BanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Mute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MuteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Mute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_Mute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Mute(ctx, req.(*MuteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Broadcast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Announcement)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Broadcast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_Broadcast_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Broadcast(ctx, req.(*Announcement))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Clocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Clocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_Clocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Clocks(ctx, req.(*ClocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Tail_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).Tail(m, &adminTailServer{stream})
+}
+
+type Admin_TailServer interface {
+	Send(*ChatMessage) error
+	grpc.ServerStream
+}
+
+type adminTailServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminTailServer) Send(m *ChatMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListParticipants",
+			Handler:    _Admin_ListParticipants_Handler,
+		},
+		{
+			MethodName: "Kick",
+			Handler:    _Admin_Kick_Handler,
+		},
+		{
+			MethodName: "Ban",
+			Handler:    _Admin_Ban_Handler,
+		},
+		{
+			MethodName: "Unban",
+			Handler:    _Admin_Unban_Handler,
+		},
+		{
+			MethodName: "Mute",
+			Handler:    _Admin_Mute_Handler,
+		},
+		{
+			MethodName: "Broadcast",
+			Handler:    _Admin_Broadcast_Handler,
+		},
+		{
+			MethodName: "Clocks",
+			Handler:    _Admin_Clocks_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Tail",
+			Handler:       _Admin_Tail_Handler,
+			ServerStreams: true,
 		},
 	},
 	Metadata: "proto/template.proto",
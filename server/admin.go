@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	"github.com/JonasSkjodt/chitty-chat/content"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var adminTokenFile = flag.String("admin-token", "", "File with the token operators call the Admin service with, turns the Admin service on")
+
+// tailBuffer is how many broadcasts may wait for a slow Admin.Tail before it misses some.
+const tailBuffer = 256
+
+// connection is where and when a participant connected, for ListParticipants and bans by IP.
+type connection struct {
+	address string // host:port
+	since   time.Time
+}
+
+// ban keeps a name or an IP from joining.
+type ban struct {
+	until  time.Time // zero until the server restarts
+	reason string
+}
+
+func (b ban) expired(now time.Time) bool {
+	return !b.until.IsZero() && now.After(b.until)
+}
+
+// removal is the reason a client is disconnected by an operator, see Kick and Ban. Its stream ends
+// with it and the participant is dropped like any other lost client, or right away if it hasn't opened its stream yet.
+type removal struct {
+	what   string // kicked or banned
+	reason string
+}
+
+func (r *removal) Error() string {
+	if r.reason == "" {
+		return r.what + " by an operator"
+	}
+	return fmt.Sprintf("%s by an operator: %s", r.what, r.reason)
+}
+
+// peerAddress is the address the call came from, empty if it is not known.
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// hostOf is the IP of a host:port address.
+func hostOf(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+// checkBan tells whether the name or the address may join. Expired bans are forgotten on the way.
+// The caller must hold s.mutex.
+func (s *chatServer) checkBan(name string, address string) error {
+	now := time.Now()
+	for _, key := range []struct {
+		bans  map[string]ban
+		value string
+	}{{s.bannedNames, name}, {s.bannedIPs, hostOf(address)}} {
+		b, ok := key.bans[key.value]
+		if !ok {
+			continue
+		}
+		if b.expired(now) {
+			delete(key.bans, key.value)
+			continue
+		}
+		return status.Error(codes.PermissionDenied, (&removal{what: "banned", reason: b.reason}).Error())
+	}
+	return nil
+}
+
+// tail is an operator following the broadcasts through Admin.Tail.
+type tail struct {
+	room     string // empty for every room
+	messages chan *gRPC.ChatMessage
+	missed   int // broadcasts it couldn't keep up with, guarded by the servers mutex
+}
+
+// publish hands the message to every tail that follows its room, without waiting for any of them.
+// The caller must hold s.mutex.
+func (s *chatServer) publish(msg *gRPC.ChatMessage) {
+	for t := range s.tails {
+		if t.room != "" && msg.Room != "" && t.room != msg.Room {
+			continue
+		}
+		select {
+		case t.messages <- msg:
+		default:
+			t.missed++
+		}
+	}
+}
+
+// closeTails ends every Admin.Tail, so they don't hold up a graceful stop.
+// The caller must hold s.mutex.
+func (s *chatServer) closeTails() {
+	for t := range s.tails {
+		close(t.messages)
+		delete(s.tails, t)
+	}
+}
+
+// adminServer is the Admin service, it works on the participants of the chat server.
+type adminServer struct {
+	gRPC.UnimplementedAdminServer
+	chat *chatServer
+}
+
+// ListParticipants tells who is connected, from where, since when and how far they are in the clocks of their rooms.
+func (a *adminServer) ListParticipants(ctx context.Context, in *gRPC.ListParticipantsRequest) (*gRPC.ParticipantList, error) {
+	s := a.chat
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	list := &gRPC.ParticipantList{}
+	for name, id := range s.clientIDs {
+		info := &gRPC.ParticipantInfo{
+			ClientName:  name,
+			ClientID:    int32(id),
+			ConnectedAt: s.connections[name].since.Unix(),
+			Address:     s.connections[name].address,
+			ClockSlots:  make(map[string]int32),
+		}
+		for _, r := range s.roomsOf(name) {
+			info.ClockSlots[r.name] = r.vectorClock[int32(id)]
+		}
+		if f, ok := s.floods[name]; ok && now.Before(f.mutedUntil) {
+			info.MutedFor = mutedNotice(f, now).MutedFor
+		}
+		list.Participants = append(list.Participants, info)
+	}
+	sort.Slice(list.Participants, func(i, j int) bool { return list.Participants[i].ClientName < list.Participants[j].ClientName })
+	return list, nil
+}
+
+// Kick disconnects the participant, its client is told why and doesn't reconnect.
+func (a *adminServer) Kick(ctx context.Context, in *gRPC.KickRequest) (*gRPC.Ack, error) {
+	s := a.chat
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.clientIDs[in.ClientName]; !ok {
+		return nil, status.Errorf(codes.NotFound, "%s is not connected", in.ClientName)
+	}
+	streaming := s.remove(in.ClientName, &removal{what: "kicked", reason: in.Reason})
+	chatlog.Event(chatlog.Admin).Participant(in.ClientName).Attr(slog.String("reason", in.Reason)).Infof("Operator kicked %s: %s", in.ClientName, orNone(in.Reason))
+	if !streaming {
+		return &gRPC.Ack{Message: fmt.Sprintf("Kicked %s, it had not opened its stream and was removed at once", in.ClientName)}, nil
+	}
+	return &gRPC.Ack{Message: fmt.Sprintf("Kicked %s", in.ClientName)}, nil
+}
+
+// remove disconnects the participant for an operator. Its stream is ended with the reason and the participant
+// is dropped when the stream ends, or right away when it hasn't opened a stream. It tells whether there was a stream.
+// The caller must hold s.mutex.
+func (s *chatServer) remove(name string, why *removal) bool {
+	if s.broadcaster.kick(name, why) {
+		return true
+	}
+	token := ""
+	for sessionToken, session := range s.sessions {
+		if session == name {
+			token = sessionToken
+		}
+	}
+	s.leave(name, token, why.Error())
+	return false
+}
+
+// Ban keeps the name, the IP or both from joining and disconnects whoever is connected under them.
+func (a *adminServer) Ban(ctx context.Context, in *gRPC.BanRequest) (*gRPC.Ack, error) {
+	if in.ClientName == "" && in.Ip == "" {
+		return nil, status.Error(codes.InvalidArgument, "ban needs a client name, an IP or both")
+	}
+	ip := ""
+	if in.Ip != "" {
+		parsed := net.ParseIP(in.Ip)
+		if parsed == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%q is not an IP", in.Ip)
+		}
+		ip = parsed.String()
+	}
+	if in.Duration < 0 {
+		return nil, status.Error(codes.InvalidArgument, "the duration must not be negative")
+	}
+
+	b := ban{reason: in.Reason}
+	until := "until the server restarts"
+	if in.Duration > 0 {
+		b.until = time.Now().Add(time.Duration(in.Duration) * time.Second)
+		until = fmt.Sprintf("for %v", time.Duration(in.Duration)*time.Second)
+	}
+
+	s := a.chat
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var banned []string
+	if in.ClientName != "" {
+		s.bannedNames[in.ClientName] = b
+		banned = append(banned, in.ClientName)
+	}
+	if ip != "" {
+		s.bannedIPs[ip] = b
+		banned = append(banned, ip)
+	}
+
+	var disconnected, removed int
+	for name := range s.clientIDs {
+		if name == in.ClientName || (ip != "" && hostOf(s.connections[name].address) == ip) {
+			if s.remove(name, &removal{what: "banned", reason: in.Reason}) {
+				disconnected++
+			} else {
+				removed++
+			}
+		}
+	}
+	chatlog.Event(chatlog.Admin).Participant(in.ClientName).Attr(slog.String("ip", ip)).Attr(slog.String("reason", in.Reason)).Infof("Operator banned %s %s: %s", strings.Join(banned, " and "), until, orNone(in.Reason))
+	reply := fmt.Sprintf("Banned %s %s, %d disconnected", strings.Join(banned, " and "), until, disconnected)
+	if removed > 0 {
+		reply += fmt.Sprintf(" and %d without a stream removed", removed)
+	}
+	return &gRPC.Ack{Message: reply}, nil
+}
+
+// Unban lets the name, the IP or both join again.
+func (a *adminServer) Unban(ctx context.Context, in *gRPC.BanRequest) (*gRPC.Ack, error) {
+	if in.ClientName == "" && in.Ip == "" {
+		return nil, status.Error(codes.InvalidArgument, "unban needs a client name, an IP or both")
+	}
+	ip := in.Ip
+	if parsed := net.ParseIP(in.Ip); parsed != nil {
+		ip = parsed.String()
+	}
+
+	s := a.chat
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var lifted []string
+	if _, ok := s.bannedNames[in.ClientName]; ok {
+		delete(s.bannedNames, in.ClientName)
+		lifted = append(lifted, in.ClientName)
+	}
+	if _, ok := s.bannedIPs[ip]; ok {
+		delete(s.bannedIPs, ip)
+		lifted = append(lifted, ip)
+	}
+	if len(lifted) == 0 {
+		return nil, status.Error(codes.NotFound, "not banned")
+	}
+	chatlog.Event(chatlog.Admin).Participant(in.ClientName).Attr(slog.String("ip", ip)).Infof("Operator unbanned %s", strings.Join(lifted, " and "))
+	return &gRPC.Ack{Message: fmt.Sprintf("Unbanned %s", strings.Join(lifted, " and "))}, nil
+}
+
+// Mute keeps the participant from sending for the duration, a duration of 0 lifts the mute.
+func (a *adminServer) Mute(ctx context.Context, in *gRPC.MuteRequest) (*gRPC.Ack, error) {
+	if in.Duration < 0 {
+		return nil, status.Error(codes.InvalidArgument, "the duration must not be negative")
+	}
+	s := a.chat
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.clientIDs[in.ClientName]; !ok {
+		return nil, status.Errorf(codes.NotFound, "%s is not connected", in.ClientName)
+	}
+	f := s.floodOf(in.ClientName)
+	duration := time.Duration(in.Duration) * time.Second
+	f.mutedUntil = time.Now().Add(duration)
+	f.muteReason = orNone(in.Reason)
+
+	if duration == 0 {
+		chatlog.Event(chatlog.Admin).Participant(in.ClientName).Infof("Operator unmuted %s", in.ClientName)
+		return &gRPC.Ack{Message: fmt.Sprintf("Unmuted %s", in.ClientName)}, nil
+	}
+	chatlog.Event(chatlog.Admin).Participant(in.ClientName).Attr(slog.String("reason", in.Reason)).Infof("Operator muted %s for %v: %s", in.ClientName, duration, orNone(in.Reason))
+	return &gRPC.Ack{Message: fmt.Sprintf("Muted %s for %v", in.ClientName, duration)}, nil
+}
+
+// Broadcast sends the announcement to every participant, or to the members of its room.
+func (a *adminServer) Broadcast(ctx context.Context, in *gRPC.Announcement) (*gRPC.Ack, error) {
+	text, rejected := content.Clean(in.Content, *maxLength)
+	if rejected != nil {
+		return nil, content.Error(rejected)
+	}
+
+	s := a.chat
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var recipients []string
+	if in.Room == "" {
+		for name := range s.clientIDs {
+			recipients = append(recipients, name)
+		}
+	} else {
+		r, ok := s.rooms[in.Room]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "there is no room %s", in.Room)
+		}
+		for name := range r.members {
+			recipients = append(recipients, name)
+		}
+	}
+
+	// every client gets the same copy, like in SendMessages
+	msg := &gRPC.ChatMessage{
+		ClientName: serverClientName,
+		Room:       in.Room,
+		Payload:    &gRPC.ChatMessage_Announcement{Announcement: &gRPC.Announcement{Content: text, Room: in.Room}},
+	}
+	for _, name := range recipients {
+		s.broadcaster.send(name, msg)
+	}
+	s.publish(msg)
+	chatlog.Event(chatlog.Admin).Room(in.Room).Attr(slog.Int("recipients", len(recipients))).Infof("Operator announced to %d participants: \"%s\"", len(recipients), text)
+	return &gRPC.Ack{Message: fmt.Sprintf("Announced to %d participants", len(recipients))}, nil
+}
+
+// Clocks tells where every room is, ordered by name.
+func (a *adminServer) Clocks(ctx context.Context, in *gRPC.ClocksRequest) (*gRPC.ClockList, error) {
+	s := a.chat
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := &gRPC.ClockList{ClockMode: s.clockMode}
+	for _, name := range sortedKeys(s.rooms) {
+		r := s.rooms[name]
+		list.Rooms = append(list.Rooms, &gRPC.RoomState{
+			Room:             r.name,
+			VectorClock:      r.vectorClock.Copy(),
+			LamportTimestamp: int32(r.lamport),
+			Sequence:         r.sequence,
+		})
+	}
+	return list, nil
+}
+
+// Tail sends the operator every broadcast, and every announcement, from now on.
+func (a *adminServer) Tail(in *gRPC.TailRequest, stream gRPC.Admin_TailServer) error {
+	s := a.chat
+	t := &tail{room: in.Room, messages: make(chan *gRPC.ChatMessage, tailBuffer)}
+
+	s.mutex.Lock()
+	if s.closing {
+		s.mutex.Unlock()
+		return errShuttingDown
+	}
+	s.tails[t] = true
+	s.mutex.Unlock()
+
+	what := in.Room
+	if what == "" {
+		what = "every room"
+	}
+	chatlog.Event(chatlog.Admin).Room(in.Room).Attr(slog.String("address", peerAddress(stream.Context()))).Infof("Operator is tailing %s", what)
+	defer func() {
+		s.mutex.Lock()
+		delete(s.tails, t)
+		missed := t.missed
+		s.mutex.Unlock()
+		chatlog.Event(chatlog.Admin).Room(in.Room).Attr(slog.Int("missed", missed)).Infof("Operator stopped tailing %s, missed %d broadcasts", what, missed)
+	}()
+
+	for {
+		select {
+		case msg, ok := <-t.messages:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func orNone(reason string) string {
+	if reason == "" {
+		return "no reason given"
+	}
+	return reason
+}
+
+// adminAuth lets only the operators call the Admin service.
+type adminAuth struct {
+	token []byte
+}
+
+func loadAdminToken(path string) (*adminAuth, error) {
+	token, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	token = []byte(strings.TrimSpace(string(token)))
+	if len(token) < 16 {
+		return nil, fmt.Errorf("the admin token in %s is too short, use at least 16 characters", path)
+	}
+	return &adminAuth{token: token}, nil
+}
+
+// check tells whether the call carries the admin token.
+func (a *adminAuth) check(ctx context.Context, method string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	headers := md.Get("authorization")
+	if len(headers) == 0 {
+		return status.Error(codes.Unauthenticated, "missing authorization, the Admin service needs the admin token")
+	}
+	scheme, token, _ := strings.Cut(headers[0], " ")
+	if !strings.EqualFold(scheme, "bearer") || subtle.ConstantTimeCompare([]byte(token), a.token) != 1 {
+		chatlog.Event(chatlog.Admin).Attr(slog.String("address", peerAddress(ctx))).Warnf("Refused %s from %s: wrong admin token", method, peerAddress(ctx))
+		return status.Error(codes.PermissionDenied, "wrong admin token")
+	}
+	return nil
+}
+
+// unaryInterceptor checks the admin token on every Admin call, other calls are left to the participant authentication.
+func (a *adminAuth) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if isAdminCall(info.FullMethod) {
+		if err := a.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor does the same for streams, like Admin.Tail.
+func (a *adminAuth) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isAdminCall(info.FullMethod) {
+		if err := a.check(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+	}
+	return handler(srv, stream)
+}
+
+// isAdminCall tells whether the method belongs to the Admin service.
+func isAdminCall(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+gRPC.Admin_ServiceDesc.ServiceName+"/")
+}
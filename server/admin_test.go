@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+)
+
+// connectOnly connects a participant without opening its stream.
+func (ts *testServer) connectOnly(t *testing.T, name string) {
+	t.Helper()
+	if _, err := gRPC.NewChatClient(ts.conn).ConnectToServer(context.Background(), &gRPC.ClientName{ClientName: name}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// connected tells whether the participant still has a session.
+func (ts *testServer) connected(name string) bool {
+	ts.chat.mutex.Lock()
+	defer ts.chat.mutex.Unlock()
+	_, ok := ts.chat.clientIDs[name]
+	return ok
+}
+
+// Kick and Ban remove a participant that hasn't opened its stream right away, there is no stream to end.
+func TestRemoveWithoutStream(t *testing.T) {
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(64, dropOldest))
+	admin := &adminServer{chat: ts.chat}
+	alice, bob := ts.join(t, "alice"), ts.join(t, "bob")
+	if alice == nil || bob == nil {
+		t.FailNow()
+	}
+
+	ts.connectOnly(t, "ghost")
+	reply, err := admin.Kick(context.Background(), &gRPC.KickRequest{ClientName: "ghost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.connected("ghost") || !strings.Contains(reply.Message, "removed") {
+		t.Errorf("kicked the ghost, still connected: %v, told %q", ts.connected("ghost"), reply.Message)
+	}
+	reply, err = admin.Kick(context.Background(), &gRPC.KickRequest{ClientName: "alice"})
+	if err != nil || reply.Message != "Kicked alice" {
+		t.Errorf("kicking alice: %v, %v", reply, err)
+	}
+	waitUntil(t, "alice is dropped with her stream", func() bool { return !ts.connected("alice") })
+
+	ts.connectOnly(t, "phantom")
+	reply, err = admin.Ban(context.Background(), &gRPC.BanRequest{ClientName: "phantom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.connected("phantom") || !strings.HasSuffix(reply.Message, "0 disconnected and 1 without a stream removed") {
+		t.Errorf("banned the phantom, still connected: %v, told %q", ts.connected("phantom"), reply.Message)
+	}
+	reply, err = admin.Ban(context.Background(), &gRPC.BanRequest{ClientName: "bob"})
+	if err != nil || !strings.HasSuffix(reply.Message, "1 disconnected") {
+		t.Errorf("banning bob: %v, %v", reply, err)
+	}
+	waitUntil(t, "bob is dropped with his stream", func() bool { return !ts.connected("bob") })
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authenticator checks the credentials of one "authorization" scheme, like Basic or Bearer,
+// and returns the name of the participant they belong to.
+type authenticator interface {
+	scheme() string
+	authenticate(credentials string) (string, error)
+}
+
+// userKey is the context key of the authenticated participant name.
+type userKey struct{}
+
+// authenticatedUser returns the participant the call was authenticated as, if authentication is on.
+func authenticatedUser(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(userKey{}).(string)
+	return name, ok
+}
+
+// auth checks the "authorization" metadata of every call against the authenticators, one per scheme.
+// With mutual TLS the common name of the client certificate is an identity too.
+type auth struct {
+	authenticators map[string]authenticator // scheme in lower case -> its authenticator
+	certificates   bool                     // whether a verified client certificate is enough to log in
+}
+
+func newAuth(certificates bool, authenticators ...authenticator) *auth {
+	a := &auth{authenticators: make(map[string]authenticator), certificates: certificates}
+	for _, authenticator := range authenticators {
+		a.authenticators[strings.ToLower(authenticator.scheme())] = authenticator
+	}
+	return a
+}
+
+// check authenticates the call and returns a context carrying the participant name.
+func (a *auth) check(ctx context.Context) (context.Context, error) {
+	certificate, hasCertificate := "", false
+	if a.certificates {
+		certificate, hasCertificate = certificateName(ctx)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	headers := md.Get("authorization")
+	if len(headers) == 0 {
+		if hasCertificate {
+			return context.WithValue(ctx, userKey{}, certificate), nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing authorization, the server needs a password, a token or a client certificate")
+	}
+
+	scheme, credentials, _ := strings.Cut(headers[0], " ")
+	authenticator, ok := a.authenticators[strings.ToLower(scheme)]
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "authorization scheme %q is not accepted", scheme)
+	}
+	name, err := authenticator.authenticate(credentials)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if hasCertificate && name != certificate {
+		return nil, status.Errorf(codes.PermissionDenied, "logged in as %q with a certificate for %q", name, certificate)
+	}
+	return context.WithValue(ctx, userKey{}, name), nil
+}
+
+// unaryInterceptor authenticates every unary call before it is handled, except health checks
+// because probes don't log in, and Admin calls which adminAuth checks.
+func (a *auth) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if isHealthCheck(info.FullMethod) || isAdminCall(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	ctx, err := a.check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor authenticates every stream before it is handled, except health checks and Admin calls.
+func (a *auth) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isHealthCheck(info.FullMethod) || isAdminCall(info.FullMethod) {
+		return handler(srv, stream)
+	}
+	ctx, err := a.check(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: stream, ctx: ctx})
+}
+
+// authStream is a stream with the authenticated participant in its context.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// passwordFile authenticates "Basic" credentials against a file with a "name:hash" line per participant,
+// where hash is the bcrypt hash of the password (see -hash-password), so the file doesn't give the passwords away.
+// Empty lines and lines starting with # are skipped.
+type passwordFile struct {
+	hashes map[string][]byte // name -> bcrypt hash of the password
+	dummy  []byte            // checked for unknown names, so the time taken doesn't tell which names exist
+}
+
+func loadPasswordFile(path string) (*passwordFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := &passwordFile{hashes: make(map[string][]byte)}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, hash, ok := strings.Cut(line, ":")
+		if !ok || name == "" || hash == "" {
+			return nil, fmt.Errorf("%s line %d: want name:hash", path, n)
+		}
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("%s line %d: the password of %s is not a bcrypt hash, make one with -hash-password %s", path, n, name, name)
+		}
+		p.hashes[name] = []byte(hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p.dummy, err = bcrypt.GenerateFromPassword([]byte("not anyones password"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *passwordFile) scheme() string {
+	return "Basic"
+}
+
+func (p *passwordFile) authenticate(credentials string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return "", fmt.Errorf("malformed basic credentials")
+	}
+	name, password, _ := strings.Cut(string(decoded), ":")
+	hash, ok := p.hashes[name]
+	if !ok {
+		hash = p.dummy
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !ok {
+		return "", fmt.Errorf("wrong name or password")
+	}
+	return name, nil
+}
+
+// hashPassword asks for the password of the participant, without showing it, and prints the line for -auth-file.
+// A password piped in is read from the first line.
+func hashPassword(name string) error {
+	var password []byte
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Password for %s: ", name)
+		typed, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return err
+		}
+		password = typed
+	} else {
+		// piped in, like echo $PASSWORD | server -hash-password alice
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		password = []byte(strings.TrimRight(line, "\r\n"))
+	}
+	if len(password) == 0 {
+		return fmt.Errorf("the password is empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s:%s\n", name, hash)
+	return nil
+}
+
+// tokenSigner authenticates "Bearer" tokens it has signed. A token is
+// base64(name).expiry.base64(signature), with the expiry in unix seconds and an HMAC-SHA256 signature
+// of the first two parts.
+type tokenSigner struct {
+	secret []byte
+}
+
+// loadTokenSigner reads the secret the tokens are signed with from a file.
+func loadTokenSigner(path string) (*tokenSigner, error) {
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret = []byte(strings.TrimSpace(string(secret)))
+	if len(secret) < 16 {
+		return nil, fmt.Errorf("the secret in %s is too short, use at least 16 characters", path)
+	}
+	return &tokenSigner{secret: secret}, nil
+}
+
+// issue makes a token for the participant that is valid until the expiry.
+func (t *tokenSigner) issue(name string, expiry time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(name)) + "." + strconv.FormatInt(expiry.Unix(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(t.sign(payload))
+}
+
+func (t *tokenSigner) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func (t *tokenSigner) scheme() string {
+	return "Bearer"
+}
+
+func (t *tokenSigner) authenticate(token string) (string, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return "", fmt.Errorf("malformed token")
+	}
+	payload := token[:i]
+	signature, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(signature, t.sign(payload)) {
+		return "", fmt.Errorf("invalid token")
+	}
+
+	encodedName, expiry, _ := strings.Cut(payload, ".")
+	name, err := base64.RawURLEncoding.DecodeString(encodedName)
+	if err != nil {
+		return "", fmt.Errorf("malformed token")
+	}
+	seconds, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("malformed token")
+	}
+	if time.Now().After(time.Unix(seconds, 0)) {
+		return "", fmt.Errorf("token expired")
+	}
+	return string(name), nil
+}
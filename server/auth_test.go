@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func writePasswordFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "users.txt")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func basic(name string, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(name + ":" + password))
+}
+
+func TestPasswordFile(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := loadPasswordFile(writePasswordFile(t, "# participants\n\nalice:"+string(hash)+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name, err := p.authenticate(basic("alice", "secret")); err != nil || name != "alice" {
+		t.Errorf("alice with the right password: got %q, %v", name, err)
+	}
+	for _, wrong := range []string{basic("alice", "guess"), basic("bob", "secret"), basic("alice", string(hash)), "not base64"} {
+		if _, err := p.authenticate(wrong); err == nil {
+			t.Errorf("%q logged in", wrong)
+		}
+	}
+}
+
+// A file with the passwords themselves, like it used to be, is refused instead of taken for hashes.
+func TestPasswordFileWithoutHashes(t *testing.T) {
+	if _, err := loadPasswordFile(writePasswordFile(t, "alice:secret\n")); err == nil {
+		t.Error("loaded a password that is not hashed")
+	}
+}
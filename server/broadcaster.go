@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+)
+
+// overflowPolicy decides what happens when a clients send queue is full.
+type overflowPolicy int
+
+const (
+	dropOldest     overflowPolicy = iota // throw away the oldest queued message to make room
+	disconnectSlow                       // disconnect the client that can't keep up
+	blockSender                          // wait until the client has room, this slows everyone down
+)
+
+// errSlowConsumer is the reason a client is disconnected under the disconnectSlow policy.
+var errSlowConsumer = errors.New("send queue is full, client is too slow")
+
+// parseOverflowPolicy turns the -overflow flag into an overflowPolicy.
+func parseOverflowPolicy(policy string) (overflowPolicy, error) {
+	switch policy {
+	case "drop-oldest":
+		return dropOldest, nil
+	case "disconnect":
+		return disconnectSlow, nil
+	case "block":
+		return blockSender, nil
+	}
+	return 0, fmt.Errorf("unknown overflow policy %q, use drop-oldest, disconnect or block", policy)
+}
+
+// clientQueue is the bounded send queue of one client, emptied by its own writer goroutine.
+type clientQueue struct {
+	name     string
+	messages chan *gRPC.ChatMessage
+	done     chan struct{} // closed when the writer has stopped for good
+	stopOnce sync.Once
+	err      error // why the writer stopped, set before done is closed
+	dropped  atomic.Uint64
+	failed   *atomic.Uint64 // the send errors of the broadcaster
+	writing  bool           // whether a writer goroutine has been started, guarded by the broadcasters mutex
+}
+
+// stop ends the queue with the given reason, only the first reason is kept.
+func (q *clientQueue) stop(err error) {
+	q.stopOnce.Do(func() {
+		q.err = err
+		close(q.done)
+	})
+}
+
+// write sends queued messages on the stream until the queue is closed, a send fails or the queue is stopped.
+// Once stopped the stream handler returns, and the stream must not be sent on after that.
+func (q *clientQueue) write(stream gRPC.Chat_MessageStreamServer) {
+	for {
+		select {
+		case <-q.done:
+			return
+		case msg, ok := <-q.messages:
+			if !ok {
+				q.stop(nil)
+				return
+			}
+			// both may be ready at once, and select picks either
+			select {
+			case <-q.done:
+				return
+			default:
+			}
+			if err := stream.Send(msg); err != nil {
+				chatlog.Event(chatlog.Queue).Participant(q.name).Err(err).Warnf("Failed to send to %s: %v", q.name, err)
+				q.failed.Add(1)
+				q.stop(err)
+				return
+			}
+		}
+	}
+}
+
+// queueStats is a snapshot of one clients send queue.
+type queueStats struct {
+	Name    string
+	Depth   int
+	Dropped uint64
+}
+
+// broadcaster fans messages out to the clients through their send queues,
+// so a slow or dead client never holds up the others.
+type broadcaster struct {
+	mutex      sync.Mutex
+	queues     map[string]*clientQueue
+	size       int
+	policy     overflowPolicy
+	dropped    atomic.Uint64 // messages dropped across all clients
+	sendErrors atomic.Uint64 // sends on a stream that failed across all clients
+}
+
+func newBroadcaster(size int, policy overflowPolicy) *broadcaster {
+	return &broadcaster{
+		queues: make(map[string]*clientQueue),
+		size:   size,
+		policy: policy,
+	}
+}
+
+// add gives the client a send queue, which collects messages until a stream is attached.
+// An existing queue for the same name is closed first.
+func (b *broadcaster) add(name string) *clientQueue {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.newQueue(name)
+}
+
+// attach starts the writer goroutine that sends the clients queue on the stream.
+// If the queue already has a writer, the client gets a fresh queue for the new stream.
+func (b *broadcaster) attach(name string, stream gRPC.Chat_MessageStreamServer) *clientQueue {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok || q.writing {
+		q = b.newQueue(name)
+	}
+	q.writing = true
+	go q.write(stream)
+	return q
+}
+
+// newQueue replaces the clients queue with an empty one.
+// The caller must hold b.mutex.
+func (b *broadcaster) newQueue(name string) *clientQueue {
+	if old, ok := b.queues[name]; ok {
+		close(old.messages)
+	}
+	q := &clientQueue{
+		name:     name,
+		messages: make(chan *gRPC.ChatMessage, b.size),
+		done:     make(chan struct{}),
+		failed:   &b.sendErrors,
+	}
+	b.queues[name] = q
+	return q
+}
+
+// remove closes the clients queue, the writer sends what is left and then stops.
+func (b *broadcaster) remove(name string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if q, ok := b.queues[name]; ok {
+		delete(b.queues, name)
+		close(q.messages)
+	}
+}
+
+// kick ends the clients stream with the reason, the client is dropped like when the stream breaks.
+// It tells whether there was a stream to end, a client that hasn't opened one yet has to be dropped by the caller.
+func (b *broadcaster) kick(name string, reason error) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		return false
+	}
+	q.stop(reason)
+	return q.writing
+}
+
+// drain closes every queue, so the writers send what is left and then end their streams.
+// It waits for the writers until the deadline and tells whether they all finished.
+func (b *broadcaster) drain(deadline time.Time) bool {
+	b.mutex.Lock()
+	var writers []*clientQueue
+	for name, q := range b.queues {
+		delete(b.queues, name)
+		close(q.messages)
+		if q.writing {
+			writers = append(writers, q)
+		}
+	}
+	b.mutex.Unlock()
+
+	timeout := time.After(time.Until(deadline))
+	for _, q := range writers {
+		select {
+		case <-q.done:
+		case <-timeout:
+			return false
+		}
+	}
+	return true
+}
+
+// send queues the message for one client, following the overflow policy if the queue is full.
+func (b *broadcaster) send(name string, msg *gRPC.ChatMessage) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		return
+	}
+	select {
+	case <-q.done:
+		return
+	default:
+	}
+
+	policy := b.policy
+	if policy == blockSender && !q.writing {
+		// nothing empties a queue before its stream is attached, so waiting on it would hang every sender
+		// (and hold the locks they hold). Keep the newest messages instead, like drop-oldest.
+		policy = dropOldest
+	}
+
+	switch policy {
+	case blockSender:
+		select {
+		case q.messages <- msg:
+		case <-q.done:
+		}
+
+	case disconnectSlow:
+		select {
+		case q.messages <- msg:
+		default:
+			q.dropped.Add(1)
+			b.dropped.Add(1)
+			q.stop(errSlowConsumer)
+		}
+
+	default:
+		for {
+			select {
+			case q.messages <- msg:
+				return
+			default:
+			}
+			// the queue is full, so make room by dropping the oldest message
+			select {
+			case <-q.messages:
+				q.dropped.Add(1)
+				b.dropped.Add(1)
+			default:
+			}
+		}
+	}
+}
+
+// stats returns the depth and drop count of every queue, and the total number of dropped messages.
+func (b *broadcaster) stats() ([]queueStats, uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	stats := make([]queueStats, 0, len(b.queues))
+	for name, q := range b.queues {
+		stats = append(stats, queueStats{Name: name, Depth: len(q.messages), Dropped: q.dropped.Load()})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats, b.dropped.Load()
+}
+
+// reportStats logs the queue stats every interval.
+func (b *broadcaster) reportStats(interval time.Duration) {
+	for range time.Tick(interval) {
+		stats, dropped := b.stats()
+		chatlog.Event(chatlog.Queue).Attr(slog.Int("clients", len(stats))).Attr(slog.Uint64("dropped", dropped)).Infof("Send queues: %d clients, %d messages dropped in total", len(stats), dropped)
+		for _, q := range stats {
+			chatlog.Event(chatlog.Queue).Participant(q.Name).Attr(slog.Int("depth", q.Depth)).Attr(slog.Uint64("dropped", q.Dropped)).Infof("Send queue %s: depth %d/%d, %d dropped", q.Name, q.Depth, b.size, q.Dropped)
+		}
+	}
+}
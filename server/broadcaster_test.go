@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+)
+
+func text(content string) *gRPC.ChatMessage {
+	return &gRPC.ChatMessage{Room: defaultRoom, Payload: &gRPC.ChatMessage_Text{Text: &gRPC.Text{Content: content}}}
+}
+
+// A client that connected but never opened its stream must not hang the senders under -overflow block.
+func TestBlockSenderWithoutStream(t *testing.T) {
+	b := newBroadcaster(2, blockSender)
+	q := b.add("idle")
+
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			b.send("idle", text(fmt.Sprint(i)))
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("send blocked on a queue without a stream")
+	}
+
+	// the queue keeps the newest messages until the stream is attached
+	if got := len(q.messages); got != 2 {
+		t.Fatalf("queue holds %d messages, want 2", got)
+	}
+	for _, want := range []string{"3", "4"} {
+		if got := (<-q.messages).GetText().GetContent(); got != want {
+			t.Errorf("queued %q, want %q", got, want)
+		}
+	}
+	if _, dropped := b.stats(); dropped != 3 {
+		t.Errorf("dropped %d messages, want 3", dropped)
+	}
+}
+
+// Once a stream is attached, -overflow block waits for the writer instead of dropping anything.
+func TestBlockSenderWaitsForWriter(t *testing.T) {
+	ts := startTestServer(t, gRPC.ClockMode_LAMPORT, newBroadcaster(1, blockSender))
+	c := ts.join(t, "reader")
+	if c == nil {
+		t.FailNow()
+	}
+	b := ts.chat.broadcaster
+	waitUntil(t, "the stream is attached", func() bool {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		return b.queues["reader"].writing
+	})
+
+	const messages = 50
+	for i := 0; i < messages; i++ {
+		b.send("reader", text(fmt.Sprint(i)))
+	}
+	for i := 0; i < messages; i++ {
+		want := fmt.Sprint(i)
+		c.waitFor(t, want, func(msg *gRPC.ChatMessage) bool { return msg.GetText().GetContent() == want })
+	}
+	if _, dropped := b.stats(); dropped != 0 {
+		t.Errorf("dropped %d messages, want none", dropped)
+	}
+}
+
+// recordingStream is a message stream that counts what is sent on it, each Send waits for release.
+type recordingStream struct {
+	gRPC.Chat_MessageStreamServer
+	sent    chan *gRPC.ChatMessage
+	release chan struct{}
+}
+
+func (s *recordingStream) Send(msg *gRPC.ChatMessage) error {
+	s.sent <- msg
+	<-s.release
+	return nil
+}
+
+// Once a queue is stopped (slow consumer or kick) its stream handler returns, so the writer must not send any more.
+func TestWriterStopsWithQueue(t *testing.T) {
+	b := newBroadcaster(8, dropOldest)
+	stream := &recordingStream{sent: make(chan *gRPC.ChatMessage, 8), release: make(chan struct{})}
+	q := b.attach("reader", stream)
+
+	b.send("reader", text("first"))
+	<-stream.sent
+	b.kick("reader", errSlowConsumer)
+	b.send("reader", text("too late"))
+	q.messages <- text("queued before the stop was noticed")
+	close(stream.release)
+
+	<-q.done
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case msg := <-stream.sent:
+		t.Errorf("sent %q after the queue was stopped", msg.GetText().GetContent())
+	default:
+	}
+}
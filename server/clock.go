@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"github.com/JonasSkjodt/chitty-chat/vclock"
+)
+
+// parseClockMode turns the -clock flag into a ClockMode.
+func parseClockMode(mode string) (gRPC.ClockMode, error) {
+	switch mode {
+	case "vector":
+		return gRPC.ClockMode_VECTOR, nil
+	case "lamport":
+		return gRPC.ClockMode_LAMPORT, nil
+	}
+	return 0, fmt.Errorf("unknown clock mode %q, use vector or lamport", mode)
+}
+
+// tickClock counts an event of the server itself in the room, like a join or a leave.
+// The caller must hold s.mutex.
+func (r *room) tickClock() {
+	if r.clockMode == gRPC.ClockMode_LAMPORT {
+		r.lamport.Tick()
+		return
+	}
+	r.vectorClock.Tick(0)
+}
+
+// stampClock puts the rooms current clock on a message the server sends.
+// The caller must hold s.mutex.
+func (r *room) stampClock(msg *gRPC.ChatMessage) {
+	if r.clockMode == gRPC.ClockMode_LAMPORT {
+		msg.LamportTimestamp = int32(r.lamport)
+		return
+	}
+	msg.VectorClock = r.vectorClock.Copy()
+}
+
+// witnessClock moves the rooms clock past the clock of a received message.
+// The caller must hold s.mutex.
+func (r *room) witnessClock(msg *gRPC.ChatMessage) {
+	if r.clockMode == gRPC.ClockMode_LAMPORT {
+		r.lamport.Witness(msg.LamportTimestamp)
+		return
+	}
+	r.UpdateVectorClock(msg.VectorClock)
+}
+
+// UpdateVectorClock merges the clock of a received message into the rooms clock.
+// Relaying a message is not an event of the server, so its own slot is not ticked.
+// The caller must hold s.mutex.
+func (r *room) UpdateVectorClock(msgVectorClock vclock.Clock) {
+	r.vectorClock.Merge(msgVectorClock)
+	for id := range r.retired {
+		r.vectorClock.Retire(id)
+	}
+}
+
+// skip counts a message of the sender that was rejected. The sender already ticked its own slot for it,
+// so without closeGap the members would hold back everything the sender sends after it.
+// The caller must hold s.mutex.
+func (r *room) skip(msg *gRPC.ChatMessage) {
+	if r.clockMode == gRPC.ClockMode_VECTOR {
+		r.skipped[msg.ClientID]++
+	}
+}
+
+// closeGap takes the rejected messages of the sender back out of its slot in the clock of the message.
+// The sender itself keeps counting them, which only makes its own clock run ahead.
+// The caller must hold s.mutex.
+func (r *room) closeGap(msg *gRPC.ChatMessage) {
+	if n := r.skipped[msg.ClientID]; n > 0 && msg.VectorClock != nil {
+		msg.VectorClock[msg.ClientID] -= n
+	}
+}
+
+// admit gives a participant that joins the room a fresh slot, it starts counting from zero again.
+// The caller must hold s.mutex.
+func (r *room) admit(id int32) {
+	delete(r.retired, id)
+	delete(r.skipped, id)
+	r.joinedAt[id] = r.vectorClock[0]
+}
+
+// stripRetired takes the slots the members no longer count out of the clock of a message before it is relayed:
+// those of participants that have left the room, and those of participants that joined again after the sender
+// last heard of them. The members drop such a slot when they deliver the Join or Leave, so an entry left in
+// would hold the message back for good. Whatever the message depends on in it comes before that Join or Leave.
+// The caller must hold s.mutex.
+func (r *room) stripRetired(msg *gRPC.ChatMessage) {
+	for id := range msg.VectorClock {
+		if id == 0 || id == msg.ClientID {
+			continue
+		}
+		if r.retired[id] || msg.VectorClock[0] < r.joinedAt[id] {
+			delete(msg.VectorClock, id)
+		}
+	}
+}
+
+// retire drops the slot of a participant that has left the room.
+// The caller must hold s.mutex.
+func (r *room) retire(id int32) {
+	r.retired[id] = true
+	delete(r.skipped, id)
+	delete(r.joinedAt, id)
+	r.vectorClock.Retire(id)
+}
+
+// clockString is the rooms current clock, for logging.
+// The caller must hold s.mutex.
+func (r *room) clockString() string {
+	if r.clockMode == gRPC.ClockMode_LAMPORT {
+		return fmt.Sprintf("lamport timestamp: %d", r.lamport)
+	}
+	return fmt.Sprintf("vector clock: %v", r.vectorClock)
+}
+
+// event starts a log record in the room, with the rooms current clock.
+// The caller must hold s.mutex.
+func (r *room) event(kind chatlog.Kind) *chatlog.Entry {
+	e := chatlog.Event(kind).Room(r.name)
+	if r.clockMode == gRPC.ClockMode_LAMPORT {
+		return e.Lamport(int32(r.lamport))
+	}
+	return e.VectorClock(r.vectorClock.Copy())
+}
+
+// messageEvent starts a log record about a message, with the clock the message was sent with.
+func messageEvent(kind chatlog.Kind, mode gRPC.ClockMode, msg *gRPC.ChatMessage) *chatlog.Entry {
+	e := chatlog.Event(kind).Participant(msg.ClientName).ClientID(msg.ClientID).Room(msg.Room)
+	if mode == gRPC.ClockMode_LAMPORT {
+		return e.Lamport(msg.LamportTimestamp)
+	}
+	return e.VectorClock(msg.VectorClock)
+}
+
+// timestamp is the clock a message was sent with, for logging.
+// Lamport timestamps are shown with the senders ClientID, which is the tiebreak of the total order.
+func timestamp(mode gRPC.ClockMode, msg *gRPC.ChatMessage) string {
+	if mode == gRPC.ClockMode_LAMPORT {
+		return fmt.Sprintf("lamport timestamp: %v", vclock.Stamp{Time: msg.LamportTimestamp, ID: msg.ClientID})
+	}
+	return fmt.Sprintf("vector clock: %v", vclock.Clock(msg.VectorClock))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"github.com/JonasSkjodt/chitty-chat/vclock"
+)
+
+// The members drop the slot of a participant when it leaves or joins again, so the server must not relay
+// entries for it that were counted before. They would hold the message back forever.
+func TestRelayedClocksDropRetiredSlots(t *testing.T) {
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(64, dropOldest))
+	alice, bob, carol := ts.join(t, "alice"), ts.join(t, "bob"), ts.join(t, "carol")
+	if alice == nil || bob == nil || carol == nil {
+		t.FailNow()
+	}
+	for _, c := range []*testClient{alice, bob, carol} {
+		if _, err := c.chat.JoinRoom(c.ctx, &gRPC.RoomRequest{Room: "#dev"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	serverSlot := func() int32 {
+		ts.chat.mutex.Lock()
+		defer ts.chat.mutex.Unlock()
+		return ts.chat.rooms["#dev"].vectorClock[0]
+	}
+	// alice sends a message with the clock she has, carol gets it the way the server relays it
+	relayed := func(content string, clock vclock.Clock) vclock.Clock {
+		msg := text(content)
+		msg.Room = "#dev"
+		msg.VectorClock = clock
+		if err := alice.stream.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+		var got vclock.Clock
+		carol.waitFor(t, content, func(msg *gRPC.ChatMessage) bool {
+			got = msg.VectorClock
+			return msg.GetText().GetContent() == content
+		})
+		return got
+	}
+
+	// bob has said something in #dev that alice has seen, and she answers after bob rejoined without noticing
+	seen := serverSlot()
+	bob.chat.LeaveRoom(bob.ctx, &gRPC.RoomRequest{Room: "#dev"})
+	if clock := relayed("while bob is away", vclock.Clock{0: seen, bob.id: 1, alice.id: 1}); clock[bob.id] != 0 {
+		t.Errorf("relayed %v, bob has left", clock)
+	}
+	bob.chat.JoinRoom(bob.ctx, &gRPC.RoomRequest{Room: "#dev"})
+	if clock := relayed("before seeing bob back", vclock.Clock{0: seen, bob.id: 1, alice.id: 2}); clock[bob.id] != 0 {
+		t.Errorf("relayed %v, that count of bob is from before he rejoined", clock)
+	}
+
+	// once alice has seen bob come back, his new messages count again
+	rejoined := serverSlot()
+	if clock := relayed("welcome back", vclock.Clock{0: rejoined, bob.id: 1, alice.id: 3}); clock[bob.id] != 1 || clock[alice.id] != 3 {
+		t.Errorf("relayed %v, want bob at 1 and alice at 3", clock)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/proto"
+)
+
+// lenientCodec is the proto codec, except that a ChatMessage with a string that is not valid UTF-8
+// comes out as a Rejected. grpc ends the whole stream when a message can't be decoded, this way
+// only the one message is dropped and the client is told why.
+type lenientCodec struct {
+	encoding.Codec
+}
+
+func (c lenientCodec) Unmarshal(data []byte, v any) error {
+	err := c.Codec.Unmarshal(data, v)
+	msg, ok := v.(*gRPC.ChatMessage)
+	// protobuf has no error value to check for, only the text
+	if err != nil && ok && strings.Contains(err.Error(), "invalid UTF-8") {
+		proto.Reset(msg)
+		msg.Payload = &gRPC.ChatMessage_Rejected{Rejected: &gRPC.Rejected{Reason: gRPC.RejectReason_INVALID_UTF8, Detail: "the message is not valid UTF-8"}}
+		return nil
+	}
+	return err
+}
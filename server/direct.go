@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	"github.com/JonasSkjodt/chitty-chat/content"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SendDirect queues a private message for the recipient and echoes it to the sender.
+// Direct messages are outside the rooms, so they get no clock or sequence number.
+func (s *chatServer) SendDirect(ctx context.Context, in *gRPC.Direct) (*gRPC.Ack, error) {
+	name, _, err := s.sessionName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	text, rejected := content.Clean(in.Content, *maxLength)
+	if rejected != nil {
+		return nil, content.Error(rejected)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rejected, drop, disconnect := s.checkFlood(name, nil, text, time.Now())
+	if disconnect {
+		s.broadcaster.kick(name, errFlooding)
+		return nil, status.Error(codes.PermissionDenied, errFlooding.Error())
+	}
+	if drop {
+		return nil, status.Error(codes.ResourceExhausted, "slow down")
+	}
+	if rejected != nil {
+		return nil, floodError(rejected)
+	}
+
+	recipient, ok := s.participant(in.RecipientName, in.RecipientID)
+	if !ok {
+		who := in.RecipientName
+		if who == "" {
+			who = fmt.Sprintf("participant %d", in.RecipientID)
+		}
+		return nil, status.Errorf(codes.NotFound, "%s is not online", who)
+	}
+
+	msg := &gRPC.ChatMessage{
+		ClientName: name,
+		ClientID:   int32(s.clientIDs[name]),
+		Payload: &gRPC.ChatMessage_Direct{Direct: &gRPC.Direct{
+			RecipientName: recipient,
+			RecipientID:   int32(s.clientIDs[recipient]),
+			Content:       text,
+		}},
+	}
+
+	// only who talked to who is logged, what they said stays private
+	chatlog.Event(chatlog.Direct).Participant(name).ClientID(msg.ClientID).Attr(slog.String("recipient", recipient)).Infof("Direct message from %s to %s", name, recipient)
+
+	s.broadcaster.send(recipient, msg)
+	if recipient != name {
+		s.broadcaster.send(name, msg)
+	}
+	return &gRPC.Ack{Message: fmt.Sprintf("Sent to %s", recipient)}, nil
+}
+
+// participant finds a connected participant by name, or by ClientID if the name is empty.
+// The caller must hold s.mutex.
+func (s *chatServer) participant(name string, id int32) (string, bool) {
+	if name != "" {
+		_, ok := s.clientIDs[name]
+		return name, ok
+	}
+	for participant, participantID := range s.clientIDs {
+		if int32(participantID) == id {
+			return participant, true
+		}
+	}
+	return "", false
+}
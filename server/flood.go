@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var rate = flag.Float64("rate", 5, "Messages per second a participant may send on average, 0 turns the limit off")
+var burst = flag.Int("burst", 10, "Messages a participant may send at once before -rate kicks in")
+var roomRate = flag.Float64("room-rate", 20, "Messages per second a room takes from all its members together, 0 turns the limit off")
+var roomBurst = flag.Int("room-burst", 40, "Messages a room takes at once before -room-rate kicks in")
+var repeatLimit = flag.Int("repeat-limit", 3, "How many times in a row a participant may send the same message, 0 turns the check off")
+var muteFor = flag.Duration("mute", 30*time.Second, "How long a participant that keeps flooding may not send")
+var forgetFloods = flag.Duration("flood-forget", 5*time.Minute, "How long a participant has to behave before earlier flooding is forgotten")
+
+// strikeGap is how long after a strike the next one can count. Without it a single burst would go
+// from warning to disconnect within milliseconds, the messages in between are only dropped.
+const strikeGap = time.Second
+
+// errFlooding is the reason a client is disconnected after it kept flooding.
+var errFlooding = errors.New("disconnected for flooding")
+
+// bucket is a token bucket, every message takes a token and tokens come back at a fixed rate.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take tells whether there was a token for a message now, and takes it. A rate of 0 means no limit.
+func (b *bucket) take(now time.Time, rate float64, burst int) bool {
+	if rate <= 0 {
+		return true
+	}
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else {
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// flood is what the server knows about how a participant sends. It is kept by name and outlives
+// the session, so reconnecting doesn't wipe the slate clean.
+type flood struct {
+	bucket
+	lastText   string
+	repeats    int       // how many times in a row lastText was sent
+	strikes    int       // 1 is a warning, 2 a mute, 3 a disconnect
+	lastStrike time.Time // strikes are forgotten -flood-forget after the last one
+	mutedUntil time.Time
+	muteReason string    // why an operator muted the participant, empty when it was muted for flooding
+	outUntil   time.Time // a participant disconnected for flooding can't come back before this
+}
+
+// checkFlood decides whether a Text or Direct from the participant may go out. It returns the rejection to tell
+// the participant about, nil to let the message through, and whether the participant must be disconnected.
+// A message that was only dropped (shortly after a strike) gets neither. r is nil for a Direct.
+// The caller must hold s.mutex.
+func (s *chatServer) checkFlood(name string, r *room, text string, now time.Time) (rejected *gRPC.Rejected, drop bool, disconnect bool) {
+	f := s.floodOf(name)
+	if f.strikes > 0 && now.Sub(f.lastStrike) > *forgetFloods {
+		f.strikes = 0
+	}
+
+	limited := !f.take(now, *rate, *burst)
+	if text == f.lastText {
+		f.repeats++
+	} else {
+		f.lastText, f.repeats = text, 1
+	}
+	repeated := *repeatLimit > 0 && f.repeats > *repeatLimit
+
+	if !limited && !repeated {
+		if now.Before(f.mutedUntil) {
+			return mutedNotice(f, now), false, false
+		}
+		// a busy room is not the fault of the sender, so it is no strike
+		if r != nil && !r.limiter.take(now, *roomRate, *roomBurst) {
+			return &gRPC.Rejected{Reason: gRPC.RejectReason_RATE_LIMITED, Detail: fmt.Sprintf("%s is too busy right now, try again in a moment", r.name)}, false, false
+		}
+		return nil, false, false
+	}
+
+	if f.strikes > 0 && now.Sub(f.lastStrike) < strikeGap {
+		return nil, true, false
+	}
+	f.strikes++
+	f.lastStrike = now
+
+	what := "sending too fast"
+	reason := gRPC.RejectReason_RATE_LIMITED
+	if repeated {
+		what = "sending the same message over and over"
+		reason = gRPC.RejectReason_REPEATED
+	}
+	switch {
+	case f.strikes >= 3:
+		// it can't come back for the length of a mute, and is still muted when it does
+		f.mutedUntil, f.muteReason = now.Add(*muteFor), ""
+		f.outUntil = f.mutedUntil
+		chatlog.Event(chatlog.Flood).Participant(name).Attr(slog.String("action", "disconnect")).Warnf("Participant %s: Disconnected for %s", name, what)
+		return nil, true, true
+	case f.strikes == 2:
+		f.mutedUntil, f.muteReason = now.Add(*muteFor), ""
+		chatlog.Event(chatlog.Flood).Participant(name).Attr(slog.String("action", "mute")).Warnf("Participant %s: Muted for %v for %s", name, *muteFor, what)
+		return mutedNotice(f, now), false, false
+	default:
+		chatlog.Event(chatlog.Flood).Participant(name).Attr(slog.String("action", "warn")).Infof("Participant %s: Warned for %s", name, what)
+		return &gRPC.Rejected{Reason: reason, Detail: fmt.Sprintf("you are %s, slow down or you will be muted", what)}, false, false
+	}
+}
+
+// checkFlooded refuses a participant that was disconnected for flooding, until it may come back.
+// Without it the client would just reconnect and the disconnect would have no effect.
+// The caller must hold s.mutex.
+func (s *chatServer) checkFlooded(name string, now time.Time) error {
+	if f, ok := s.floods[name]; ok && now.Before(f.outUntil) {
+		return status.Errorf(codes.PermissionDenied, "%v, come back in %v", errFlooding, f.outUntil.Sub(now).Round(time.Second))
+	}
+	return nil
+}
+
+// floodOf returns what the server knows about how the participant sends, starting from nothing.
+// The caller must hold s.mutex.
+func (s *chatServer) floodOf(name string) *flood {
+	f, ok := s.floods[name]
+	if !ok {
+		f = &flood{}
+		s.floods[name] = f
+	}
+	return f
+}
+
+// mutedNotice tells a muted participant how long it has to wait.
+func mutedNotice(f *flood, now time.Time) *gRPC.Rejected {
+	left := f.mutedUntil.Sub(now).Round(time.Second)
+	why := "for flooding"
+	if f.muteReason != "" {
+		why = fmt.Sprintf("by an operator (%s)", f.muteReason)
+	}
+	return &gRPC.Rejected{
+		Reason:   gRPC.RejectReason_MUTED,
+		Detail:   fmt.Sprintf("you are muted %s, wait %v", why, left),
+		MutedFor: int32(math.Ceil(left.Seconds())),
+	}
+}
+
+// floodError turns a rejection for flooding into the status SendDirect fails with.
+func floodError(rejected *gRPC.Rejected) error {
+	st, err := status.New(codes.ResourceExhausted, rejected.Detail).WithDetails(rejected)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, rejected.Detail)
+	}
+	return st.Err()
+}
+
+// forgetFlood drops what the server knows about a participant that has left, unless it was flooding.
+// The caller must hold s.mutex.
+func (s *chatServer) forgetFlood(name string) {
+	if f, ok := s.floods[name]; ok && f.strikes == 0 && time.Now().After(f.mutedUntil) {
+		delete(s.floods, name)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// floodLimits sets the flood flags for one test, TestMain turns them off for the others.
+func floodLimits(t *testing.T, perSecond float64, atOnce int) {
+	oldRate, oldBurst, oldRepeat := *rate, *burst, *repeatLimit
+	t.Cleanup(func() { *rate, *burst, *repeatLimit = oldRate, oldBurst, oldRepeat })
+	*rate, *burst, *repeatLimit = perSecond, atOnce, 0
+}
+
+// Flooding gets a warning, then a mute and then a disconnect, with strikeGap between the strikes.
+func TestFloodEscalation(t *testing.T) {
+	floodLimits(t, 0.001, 1)
+	s := newChatServer("test", "0", gRPC.ClockMode_VECTOR, newBroadcaster(8, dropOldest), func(string) (historyStore, error) { return newRingHistory(8), nil }, 5)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	start := time.Now()
+	for _, step := range []struct {
+		after      time.Duration
+		want       gRPC.RejectReason // what the participant is told, -1 for nothing
+		drop       bool
+		disconnect bool
+	}{
+		{0, -1, false, false},                                    // within the burst
+		{0, gRPC.RejectReason_RATE_LIMITED, false, false},        // warned
+		{500 * time.Millisecond, -1, true, false},                // too soon after the warning to count, only dropped
+		{2 * time.Second, gRPC.RejectReason_MUTED, false, false}, // muted
+		{2200 * time.Millisecond, -1, true, false},               // dropped again
+		{4 * time.Second, -1, true, true},                        // disconnected
+	} {
+		rejected, drop, disconnect := s.checkFlood("alice", nil, "hello", start.Add(step.after))
+		got := gRPC.RejectReason(-1)
+		if rejected != nil {
+			got = rejected.Reason
+		}
+		if got != step.want || drop != step.drop || disconnect != step.disconnect {
+			t.Errorf("after %v: told %v, dropped %v, disconnected %v, want %v, %v, %v", step.after, got, drop, disconnect, step.want, step.drop, step.disconnect)
+		}
+	}
+
+	// it is kept out for as long as a mute lasts
+	gone := start.Add(4 * time.Second)
+	if err := s.checkFlooded("alice", gone.Add(time.Second)); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("right after the disconnect: %v, want PermissionDenied", err)
+	}
+	if err := s.checkFlooded("alice", gone.Add(*muteFor+time.Second)); err != nil {
+		t.Errorf("after the mute: %v", err)
+	}
+}
+
+// A client disconnected for flooding is told it must not come back, and the server refuses it if it tries.
+func TestFloodDisconnectIsFinal(t *testing.T) {
+	floodLimits(t, 0.001, 1)
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(64, dropOldest))
+	alice := ts.join(t, "alice")
+	if alice == nil {
+		t.FailNow()
+	}
+	// alice has been warned and muted already, and has nothing left to send with
+	ts.chat.mutex.Lock()
+	ts.chat.floods["alice"] = &flood{bucket: bucket{last: time.Now()}, strikes: 2, lastStrike: time.Now().Add(-2 * strikeGap)}
+	ts.chat.mutex.Unlock()
+
+	alice.say(defaultRoom, "one more")
+	timeout := time.After(10 * time.Second)
+	for ended := false; !ended; {
+		select {
+		case _, ok := <-alice.received:
+			ended = !ok
+		case <-timeout:
+			t.Fatal("alice is still connected")
+		}
+	}
+	if status.Code(alice.err) != codes.PermissionDenied {
+		t.Errorf("the stream ended with %v, want PermissionDenied", alice.err)
+	}
+
+	_, err := alice.chat.ResumeSession(context.Background(), &gRPC.ResumeRequest{ClientName: "alice", ClientID: alice.id, SessionToken: alice.token})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("resuming: %v, want PermissionDenied", err)
+	}
+	_, err = alice.chat.ConnectToServer(context.Background(), &gRPC.ClientName{ClientName: "alice"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("connecting again: %v, want PermissionDenied", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"net"
+	"strings"
+	"sync"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+var reflectionFlag = flag.Bool("reflection", false, "Serve gRPC reflection, so tools like grpcurl can call the server without the proto file")
+
+// registerHealth adds the standard grpc.health.v1 Health service. It reports NOT_SERVING, for the server
+// as a whole ("") and for each of services, until the server is ready and again once it shuts down.
+func registerHealth(grpcServer *grpc.Server, services []string) *health.Server {
+	h := health.NewServer()
+	h.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	for _, service := range services {
+		h.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	healthpb.RegisterHealthServer(grpcServer, h)
+	return h
+}
+
+// serving marks the server and every service it reports on as SERVING.
+// After shutdown it does nothing, the Health service stays NOT_SERVING.
+func (s *chatServer) serving() {
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	for _, service := range s.healthServices {
+		s.health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+}
+
+// registerServices registers everything the server serves on grpcServer: the Chat service, the Health
+// service, the Admin service with -admin-token and server reflection with -reflection.
+// Everything reports NOT_SERVING until serving is called.
+func registerServices(grpcServer *grpc.Server, server *chatServer, withAdmin bool, withReflection bool) {
+	gRPC.RegisterChatServer(grpcServer, server) //Registers the server to the gRPC server.
+	server.healthServices = []string{gRPC.Chat_ServiceDesc.ServiceName}
+	if withAdmin {
+		gRPC.RegisterAdminServer(grpcServer, &adminServer{chat: server})
+		server.healthServices = append(server.healthServices, gRPC.Admin_ServiceDesc.ServiceName)
+	}
+	server.health = registerHealth(grpcServer, server.healthServices)
+	if withReflection {
+		reflection.Register(grpcServer)
+	}
+}
+
+// servingListener calls ready the first time grpc.Server.Serve accepts on the listener, so probes only
+// see SERVING once connections are actually taken, and not while Serve may still fail.
+type servingListener struct {
+	net.Listener
+	once  sync.Once
+	ready func()
+}
+
+func (l *servingListener) Accept() (net.Conn, error) {
+	l.once.Do(l.ready)
+	return l.Listener.Accept()
+}
+
+// isHealthCheck tells whether the method belongs to the Health service, which probes call without logging in.
+func isHealthCheck(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var healthServices = []string{"", gRPC.Chat_ServiceDesc.ServiceName, gRPC.Admin_ServiceDesc.ServiceName}
+
+// statuses asks the Health service about the server and each of its services.
+func statuses(t *testing.T, h healthpb.HealthServer) map[string]healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	got := make(map[string]healthpb.HealthCheckResponse_ServingStatus)
+	for _, service := range healthServices {
+		reply, err := h.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("checking %q: %v", service, err)
+		}
+		got[service] = reply.Status
+	}
+	return got
+}
+
+// allAre tells whether every service has the status.
+func allAre(got map[string]healthpb.HealthCheckResponse_ServingStatus, want healthpb.HealthCheckResponse_ServingStatus) bool {
+	for _, status := range got {
+		if status != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Probes must not see SERVING before Serve takes connections, nor after the server starts shutting down.
+func TestHealthFollowsServing(t *testing.T) {
+	chat := newChatServer("test", "0", gRPC.ClockMode_VECTOR, newBroadcaster(8, dropOldest), func(string) (historyStore, error) { return newRingHistory(8), nil }, 5)
+	grpcServer := grpc.NewServer()
+	registerServices(grpcServer, chat, true, false)
+	if got := statuses(t, chat.health); !allAre(got, healthpb.HealthCheckResponse_NOT_SERVING) {
+		t.Errorf("before serving: %v", got)
+	}
+
+	list := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(&servingListener{Listener: list, ready: chat.serving})
+	defer grpcServer.Stop()
+	probe := healthpb.NewHealthClient(dialBufconn(t, list))
+	waitUntil(t, "SERVING", func() bool {
+		reply, err := probe.Check(context.Background(), &healthpb.HealthCheckRequest{Service: gRPC.Admin_ServiceDesc.ServiceName})
+		return err == nil && reply.Status == healthpb.HealthCheckResponse_SERVING
+	})
+	if got := statuses(t, chat.health); !allAre(got, healthpb.HealthCheckResponse_SERVING) {
+		t.Errorf("while serving: %v", got)
+	}
+
+	chat.shutdown("test", 0, time.Now().Add(time.Second))
+	if got := statuses(t, chat.health); !allAre(got, healthpb.HealthCheckResponse_NOT_SERVING) {
+		t.Errorf("after shutdown: %v", got)
+	}
+}
+
+// Without -admin-token there is no Admin service to report on.
+func TestHealthWithoutAdmin(t *testing.T) {
+	chat := newChatServer("test", "0", gRPC.ClockMode_VECTOR, newBroadcaster(8, dropOldest), func(string) (historyStore, error) { return newRingHistory(8), nil }, 5)
+	registerServices(grpc.NewServer(), chat, false, false)
+	chat.serving()
+	_, err := chat.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: gRPC.Admin_ServiceDesc.ServiceName})
+	if err == nil {
+		t.Error("the Health service reports on the Admin service, which is not registered")
+	}
+}
+
+func TestReflectionListsServices(t *testing.T) {
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(8, dropOldest))
+	stream, err := reflectionpb.NewServerReflectionClient(ts.conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.CloseSend()
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := make(map[string]bool)
+	for _, service := range reply.GetListServicesResponse().GetService() {
+		listed[service.Name] = true
+	}
+	for _, want := range []string{gRPC.Chat_ServiceDesc.ServiceName, gRPC.Admin_ServiceDesc.ServiceName, healthpb.Health_ServiceDesc.ServiceName} {
+		if !listed[want] {
+			t.Errorf("reflection doesn't list %s, only %v", want, listed)
+		}
+	}
+}
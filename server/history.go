@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// historyStore keeps the broadcasts of the server, with their sequence numbers and clocks,
+// so they can be resent, replayed to new clients and paged through with the History rpc.
+type historyStore interface {
+	// Append keeps a broadcast. Broadcasts are appended in sequence order.
+	Append(msg *gRPC.ChatMessage) error
+	// Range returns the kept broadcasts numbered from..to (both included), oldest first.
+	// Broadcasts that are no longer kept are left out.
+	Range(from int64, to int64) ([]*gRPC.ChatMessage, error)
+	// Last is the sequence number of the newest kept broadcast, 0 if there is none.
+	Last() int64
+	// Close writes out anything still buffered.
+	Close() error
+}
+
+// ringHistory keeps the latest broadcasts in memory, the oldest are forgotten when it is full.
+type ringHistory struct {
+	mutex    sync.Mutex
+	messages []*gRPC.ChatMessage // oldest first
+	size     int
+}
+
+func newRingHistory(size int) *ringHistory {
+	return &ringHistory{size: size}
+}
+
+func (h *ringHistory) Append(msg *gRPC.ChatMessage) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.messages = append(h.messages, msg)
+	if len(h.messages) > h.size {
+		h.messages = h.messages[len(h.messages)-h.size:]
+	}
+	return nil
+}
+
+func (h *ringHistory) Range(from int64, to int64) ([]*gRPC.ChatMessage, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var found []*gRPC.ChatMessage
+	for _, msg := range h.messages {
+		if msg.Sequence >= from && msg.Sequence <= to {
+			found = append(found, msg)
+		}
+	}
+	return found, nil
+}
+
+func (h *ringHistory) Last() int64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.messages) == 0 {
+		return 0
+	}
+	return h.messages[len(h.messages)-1].Sequence
+}
+
+func (h *ringHistory) Close() error {
+	return nil
+}
+
+// fileHistory is an append-only log of every broadcast. Each record is a 4 byte big endian length
+// followed by the marshalled ChatMessage. Only the offsets are kept in memory.
+type fileHistory struct {
+	mutex   sync.Mutex
+	file    *os.File
+	size    int64           // where the next record goes
+	offsets map[int64]int64 // sequence number -> offset of its record
+	last    int64
+}
+
+// openFileHistory opens (or creates) the log at path and reads back the offsets of the records in it.
+// A half written record at the end, left by a crash, is cut off.
+func openFileHistory(path string) (*fileHistory, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	h := &fileHistory{file: f, offsets: make(map[int64]int64)}
+
+	for {
+		msg, n, err := h.readAt(h.size)
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("reading history record at offset %d: %w", h.size, err)
+		}
+		h.offsets[msg.Sequence] = h.size
+		h.last = max(h.last, msg.Sequence)
+		h.size += n
+	}
+	if err := f.Truncate(h.size); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// readAt reads the record at the offset and returns it with its length in bytes.
+// The caller must hold h.mutex, or be the only user of h.
+func (h *fileHistory) readAt(offset int64) (*gRPC.ChatMessage, int64, error) {
+	var header [4]byte
+	if _, err := h.file.ReadAt(header[:], offset); err != nil {
+		return nil, 0, err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := h.file.ReadAt(data, offset+4); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	msg := &gRPC.ChatMessage{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, 0, err
+	}
+	return msg, int64(4 + len(data)), nil
+}
+
+func (h *fileHistory) Append(msg *gRPC.ChatMessage) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	record := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	record = append(record, data...)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, err := h.file.WriteAt(record, h.size); err != nil {
+		return err
+	}
+	h.offsets[msg.Sequence] = h.size
+	h.last = max(h.last, msg.Sequence)
+	h.size += int64(len(record))
+	return nil
+}
+
+func (h *fileHistory) Range(from int64, to int64) ([]*gRPC.ChatMessage, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var found []*gRPC.ChatMessage
+	for seq := max(from, 1); seq <= min(to, h.last); seq++ {
+		offset, ok := h.offsets[seq]
+		if !ok {
+			continue
+		}
+		msg, _, err := h.readAt(offset)
+		if err != nil {
+			return found, err
+		}
+		found = append(found, msg)
+	}
+	return found, nil
+}
+
+func (h *fileHistory) Last() int64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.last
+}
+
+func (h *fileHistory) Close() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err := h.file.Sync(); err != nil {
+		h.file.Close()
+		return err
+	}
+	return h.file.Close()
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+)
+
+// broadcastNumbered is a broadcast as the history gets it, with its sequence number.
+func broadcastNumbered(seq int64) *gRPC.ChatMessage {
+	msg := text(fmt.Sprint("message ", seq))
+	msg.Sequence = seq
+	msg.VectorClock = map[int32]int32{0: int32(seq)}
+	return msg
+}
+
+// sequences lists the sequence numbers of the broadcasts, to compare them.
+func sequences(msgs []*gRPC.ChatMessage) string {
+	var seqs []int64
+	for _, msg := range msgs {
+		seqs = append(seqs, msg.Sequence)
+	}
+	return fmt.Sprint(seqs)
+}
+
+func TestHistoryStores(t *testing.T) {
+	file, err := openFileHistory(filepath.Join(t.TempDir(), "general.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, tc := range []struct {
+		name    string
+		history historyStore
+		kept    string // what Range(1, 8) returns after appending 1..8
+	}{
+		{"ring", newRingHistory(5), "[4 5 6 7 8]"},
+		{"file", file, "[1 2 3 4 5 6 7 8]"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if last := tc.history.Last(); last != 0 {
+				t.Errorf("an empty history ends at %d", last)
+			}
+			for seq := int64(1); seq <= 8; seq++ {
+				if err := tc.history.Append(broadcastNumbered(seq)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if last := tc.history.Last(); last != 8 {
+				t.Errorf("Last() = %d, want 8", last)
+			}
+			for _, r := range []struct {
+				from, to int64
+				want     string
+			}{
+				{1, 8, tc.kept},
+				{6, 7, "[6 7]"},
+				{7, 100, "[7 8]"},
+				{9, 12, "[]"},
+				{5, 4, "[]"},
+			} {
+				got, err := tc.history.Range(r.from, r.to)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if sequences(got) != r.want {
+					t.Errorf("Range(%d, %d) = %s, want %s", r.from, r.to, sequences(got), r.want)
+				}
+			}
+			got, _ := tc.history.Range(3, 8)
+			if len(got) > 0 && got[len(got)-1].GetText().GetContent() != "message 8" {
+				t.Errorf("broadcast 8 came back as %v", got[len(got)-1])
+			}
+		})
+	}
+}
+
+// The history in -history-dir is read back when the server starts again.
+func TestFileHistoryReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "general.log")
+	h, err := openFileHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq := int64(1); seq <= 3; seq++ {
+		h.Append(broadcastNumbered(seq))
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err = openFileHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	if last := h.Last(); last != 3 {
+		t.Errorf("reopened history ends at %d, want 3", last)
+	}
+	h.Append(broadcastNumbered(4))
+	if got, _ := h.Range(1, 4); sequences(got) != "[1 2 3 4]" {
+		t.Errorf("after reopening and appending: %s", sequences(got))
+	}
+}
+
+// A record the server was writing when it crashed is cut off, the ones before it are kept.
+func TestFileHistoryTruncatedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "general.log")
+	h, err := openFileHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq := int64(1); seq <= 3; seq++ {
+		h.Append(broadcastNumbered(seq))
+	}
+	h.Close()
+	info, _ := os.Stat(path)
+	whole := info.Size()
+
+	for name, partial := range map[string][]byte{
+		"header":      {0, 0},
+		"record body": {0, 0, 0, 100, 1, 2, 3},
+	} {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(partial)
+		f.Close()
+
+		h, err = openFileHistory(path)
+		if err != nil {
+			t.Fatalf("half a %s: %v", name, err)
+		}
+		if info, _ := os.Stat(path); info.Size() != whole {
+			t.Errorf("half a %s: the file is %d bytes, want %d", name, info.Size(), whole)
+		}
+		if got, _ := h.Range(1, 10); h.Last() != 3 || sequences(got) != "[1 2 3]" {
+			t.Errorf("half a %s: kept %s", name, sequences(got))
+		}
+		h.Close()
+	}
+
+	// the next broadcast goes where the cut off record was
+	h, _ = openFileHistory(path)
+	defer h.Close()
+	h.Append(broadcastNumbered(4))
+	if got, err := h.Range(1, 4); err != nil || sequences(got) != "[1 2 3 4]" {
+		t.Errorf("after the cut: %s, %v", sequences(got), err)
+	}
+}
+
+// A client can't ask for more than historyPage broadcasts at once.
+func TestHistoryPageIsCapped(t *testing.T) {
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(1024, dropOldest))
+	alice := ts.join(t, "alice")
+	if alice == nil {
+		t.FailNow()
+	}
+	const sent = historyPage + 50
+	for i := 1; i <= sent; i++ {
+		alice.say(defaultRoom, fmt.Sprint("message ", i))
+	}
+	last := fmt.Sprint("message ", sent)
+	alice.waitFor(t, last, func(msg *gRPC.ChatMessage) bool { return msg.GetText().GetContent() == last })
+
+	stream, err := alice.chat.History(alice.ctx, &gRPC.HistoryRequest{Room: defaultRoom, Limit: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := 0
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got++
+	}
+	if got != historyPage {
+		t.Errorf("got %d broadcasts, want %d", got, historyPage)
+	}
+}
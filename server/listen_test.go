@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/dial"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Clients join with the address the server prints at startup, so whatever -listen and -port say,
+// dialing that address the way the client does must reach the server.
+func TestListenAndAdvertise(t *testing.T) {
+	defer func(listenFlag, portFlag, advertiseFlag string) {
+		*listenAddr, *port, *advertiseAddr = listenFlag, portFlag, advertiseFlag
+	}(*listenAddr, *port, *advertiseAddr)
+
+	for _, tc := range []struct {
+		listen, port, advertise string
+	}{
+		{"127.0.0.1:0", "", ""},
+		{"[::1]:0", "", ""},
+		{"localhost:0", "", ""},
+		{"", "0", ""},         // localhost at -port
+		{"0.0.0.0:0", "", ""}, // every interface, the outbound IP is advertised
+		{":0", "", ""},
+		{"127.0.0.1:0", "", "chat.example.com:5400"},
+	} {
+		*listenAddr, *port, *advertiseAddr = tc.listen, tc.port, tc.advertise
+		list, err := listen()
+		if err != nil {
+			if tc.listen == "[::1]:0" {
+				t.Logf("no IPv6 loopback here: %v", err)
+				continue
+			}
+			t.Fatalf("-listen %q -port %q: %v", tc.listen, tc.port, err)
+		}
+		advertised := advertise(list.Addr().(*net.TCPAddr))
+		if tc.advertise != "" {
+			list.Close()
+			if advertised != tc.advertise {
+				t.Errorf("-advertise %s: advertised %s", tc.advertise, advertised)
+			}
+			continue
+		}
+
+		chat := newChatServer("test", "0", gRPC.ClockMode_VECTOR, newBroadcaster(8, dropOldest), func(string) (historyStore, error) { return newRingHistory(8), nil }, 5)
+		grpcServer := grpc.NewServer()
+		registerServices(grpcServer, chat, false, false)
+		go grpcServer.Serve(&servingListener{Listener: list, ready: chat.serving})
+
+		if err := joinAt(dial.Target(advertised)); err != nil {
+			t.Errorf("-listen %q -port %q: joining at the advertised %s: %v", tc.listen, tc.port, advertised, err)
+		}
+		grpcServer.Stop()
+	}
+}
+
+// joinAt connects a participant to the server at the target.
+func joinAt(target string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = gRPC.NewChatClient(conn).ConnectToServer(ctx, &gRPC.ClientName{ClientName: "alice"}, grpc.WaitForReady(true))
+	return err
+}
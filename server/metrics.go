@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+)
+
+var metricsAddr = flag.String("metrics", "", "Address to serve Prometheus metrics on at /metrics, like localhost:9400. Empty turns it off")
+
+// fanoutBuckets are the upper bounds of the fan-out latency histogram, in seconds.
+// Queueing a broadcast is normally a matter of microseconds, unless -overflow block makes it wait.
+var fanoutBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// metrics counts what the server does, for the /metrics endpoint. Gauges like the number of
+// participants are not kept here, they are read from the server when metrics are scraped.
+type metrics struct {
+	mutex      sync.Mutex
+	joins      map[string]uint64 // room -> participants that joined it
+	leaves     map[string]uint64 // room -> participants that left it
+	received   map[string]uint64 // payload -> messages received on a stream
+	broadcasts map[string]uint64 // room -> messages broadcast in it
+	deliveries uint64            // broadcasts times the members they were queued for
+	fanout     histogram         // how long SendMessages took
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		joins:      make(map[string]uint64),
+		leaves:     make(map[string]uint64),
+		received:   make(map[string]uint64),
+		broadcasts: make(map[string]uint64),
+		fanout:     histogram{bounds: fanoutBuckets, counts: make([]uint64, len(fanoutBuckets))},
+	}
+}
+
+// histogram counts observations per bucket, the way Prometheus wants them.
+type histogram struct {
+	bounds []float64
+	counts []uint64 // observations <= bounds[i] and > bounds[i-1]
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(v float64) {
+	h.sum += v
+	h.count++
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+func (m *metrics) joined(room string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.joins[room]++
+}
+
+func (m *metrics) left(room string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.leaves[room]++
+}
+
+// forgetRoom drops the series of a room that was removed. Room names are up to the clients,
+// so keeping them would let the memory and the label count grow without limit.
+func (m *metrics) forgetRoom(room string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.joins, room)
+	delete(m.leaves, room)
+	delete(m.broadcasts, room)
+}
+
+// receivedMessage counts a message from a client by its payload, like text or presence.
+func (m *metrics) receivedMessage(msg *gRPC.ChatMessage) {
+	payload := "none"
+	reflected := msg.ProtoReflect()
+	if field := reflected.WhichOneof(reflected.Descriptor().Oneofs().ByName("payload")); field != nil {
+		payload = string(field.Name())
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.received[payload]++
+}
+
+// broadcast counts a broadcast queued for the members of the room, and how long that took.
+func (m *metrics) broadcast(room string, members int, took time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.broadcasts[room]++
+	m.deliveries += uint64(members)
+	m.fanout.observe(took.Seconds())
+}
+
+// serveMetrics serves the metrics of the server at /metrics on the address, until the returned server is closed.
+func (s *chatServer) serveMetrics(address string) (*http.Server, error) {
+	list, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.writeMetrics(w)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := server.Serve(list); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverEvent(chatlog.Startup).Err(err).Errorf("Server %s: Metrics stopped: %v", s.name, err)
+		}
+	}()
+	serverEvent(chatlog.Startup).Attr(slog.String("address", list.Addr().String())).Infof("Server %s: Metrics at http://%v/metrics", s.name, list.Addr())
+	return server, nil
+}
+
+// writeMetrics writes every metric in the Prometheus text format.
+func (s *chatServer) writeMetrics(w io.Writer) {
+	// the gauges are read from the server itself
+	s.mutex.Lock()
+	participants := len(s.clientIDs)
+	members := make(map[string]int)
+	clockWidth := make(map[string]int)
+	for name, r := range s.rooms {
+		members[name] = len(r.members)
+		clockWidth[name] = len(r.vectorClock)
+	}
+	s.mutex.Unlock()
+	queues, dropped := s.broadcaster.stats()
+
+	p := promWriter{w: w}
+	p.header("chitty_participants", "gauge", "Participants connected to the server.")
+	p.sample("chitty_participants", "", float64(participants))
+
+	p.header("chitty_room_members", "gauge", "Participants in each room.")
+	for _, room := range sortedKeys(members) {
+		p.sample("chitty_room_members", labels("room", room), float64(members[room]))
+	}
+	p.header("chitty_vector_clock_width", "gauge", "Entries in the vector clock of each room, grows with everyone that has sent something in it.")
+	for _, room := range sortedKeys(clockWidth) {
+		p.sample("chitty_vector_clock_width", labels("room", room), float64(clockWidth[room]))
+	}
+
+	p.header("chitty_queue_depth", "gauge", "Messages waiting in the send queue of each client.")
+	for _, q := range queues {
+		p.sample("chitty_queue_depth", labels("participant", q.Name), float64(q.Depth))
+	}
+	p.header("chitty_messages_dropped_total", "counter", "Messages dropped because a send queue was full.")
+	p.sample("chitty_messages_dropped_total", "", float64(dropped))
+	p.header("chitty_send_errors_total", "counter", "Sends on a client stream that failed.")
+	p.sample("chitty_send_errors_total", "", float64(s.broadcaster.sendErrors.Load()))
+
+	m := s.metrics
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	p.header("chitty_joins_total", "counter", "Participants that joined a room.")
+	for _, room := range sortedKeys(m.joins) {
+		p.sample("chitty_joins_total", labels("room", room), float64(m.joins[room]))
+	}
+	p.header("chitty_leaves_total", "counter", "Participants that left a room, by themselves or not.")
+	for _, room := range sortedKeys(m.leaves) {
+		p.sample("chitty_leaves_total", labels("room", room), float64(m.leaves[room]))
+	}
+	p.header("chitty_messages_received_total", "counter", "Messages received from clients on their streams, by payload.")
+	for _, payload := range sortedKeys(m.received) {
+		p.sample("chitty_messages_received_total", labels("payload", payload), float64(m.received[payload]))
+	}
+	p.header("chitty_messages_broadcast_total", "counter", "Messages broadcast to the members of a room, joins and leaves included.")
+	for _, room := range sortedKeys(m.broadcasts) {
+		p.sample("chitty_messages_broadcast_total", labels("room", room), float64(m.broadcasts[room]))
+	}
+	p.header("chitty_deliveries_total", "counter", "Broadcasts queued for a member, one broadcast to ten members counts ten.")
+	p.sample("chitty_deliveries_total", "", float64(m.deliveries))
+
+	p.header("chitty_fanout_seconds", "histogram", "How long it took to queue a broadcast for every member of the room.")
+	var cumulative uint64
+	for i, bound := range m.fanout.bounds {
+		cumulative += m.fanout.counts[i]
+		p.sample("chitty_fanout_seconds_bucket", labels("le", strconv.FormatFloat(bound, 'g', -1, 64)), float64(cumulative))
+	}
+	p.sample("chitty_fanout_seconds_bucket", labels("le", "+Inf"), float64(m.fanout.count))
+	p.sample("chitty_fanout_seconds_sum", "", m.fanout.sum)
+	p.sample("chitty_fanout_seconds_count", "", float64(m.fanout.count))
+}
+
+// promWriter writes the Prometheus text format.
+type promWriter struct {
+	w io.Writer
+}
+
+func (p promWriter) header(name string, kind string, help string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p promWriter) sample(name string, labels string, value float64) {
+	fmt.Fprintf(p.w, "%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// labels formats label pairs as {name="value",...}, with the values escaped.
+func labels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(pairs[i+1])
+		fmt.Fprintf(&b, "%s=\"%s\"", pairs[i], value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
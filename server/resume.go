@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResumeSession lets a client that lost its connection join again as the same participant.
+// If the server still has the old session (it hasn't noticed the connection is gone yet), that session
+// is ended first. The client keeps its ClientID unless someone else got it in the meantime, like after
+// a restart of the server, and gets the broadcasts it missed in each of its rooms.
+func (s *chatServer) ResumeSession(ctx context.Context, in *gRPC.ResumeRequest) (*gRPC.ResumeReply, error) {
+	if err := checkName(ctx, in.ClientName); err != nil {
+		return nil, err
+	}
+
+	token, err := newSessionToken()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not create session: %v", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closing {
+		return nil, errShuttingDown
+	}
+	if err := s.checkBan(in.ClientName, peerAddress(ctx)); err != nil {
+		return nil, err
+	}
+	if err := s.checkFlooded(in.ClientName, time.Now()); err != nil {
+		return nil, err
+	}
+	if name, ok := s.sessions[in.SessionToken]; ok && name == in.ClientName {
+		s.leave(name, in.SessionToken, "reconnecting")
+	}
+	if _, taken := s.clientIDs[in.ClientName]; taken {
+		return nil, status.Errorf(codes.AlreadyExists, "the name %q is already taken", in.ClientName)
+	}
+
+	id := int(in.ClientID)
+	if id <= 0 || s.idTaken(id) {
+		id = s.clientID
+	}
+	// ClientIDs handed out from here on must not clash with the kept one
+	s.clientID = max(s.clientID, id+1)
+	s.register(in.ClientName, id, token, peerAddress(ctx))
+
+	positions := in.Rooms
+	if len(positions) == 0 {
+		positions = []*gRPC.RoomPosition{{Room: defaultRoom}}
+	}
+	reply := &gRPC.ResumeReply{ClientID: int32(id), SessionToken: token, ClockMode: s.clockMode, MaxLength: int32(*maxLength)}
+	for _, position := range positions {
+		state, err := s.joinRoom(in.ClientName, position.Room, position.LastSequence)
+		if err != nil {
+			// the rest of the rooms can still be resumed
+			chatlog.Event(chatlog.Join).Participant(in.ClientName).ClientID(int32(id)).Room(position.Room).Err(err).Warnf("Participant %s could not go back to %s: %v", in.ClientName, position.Room, err)
+			continue
+		}
+		reply.Rooms = append(reply.Rooms, state)
+	}
+	return reply, nil
+}
+
+// idTaken tells whether a connected participant has the ClientID.
+// The caller must hold s.mutex.
+func (s *chatServer) idTaken(id int) bool {
+	for _, participantID := range s.clientIDs {
+		if participantID == id {
+			return true
+		}
+	}
+	return false
+}
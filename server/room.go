@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"github.com/JonasSkjodt/chitty-chat/vclock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultRoom is the room every participant starts out in.
+const defaultRoom = "#general"
+
+var maxRooms = flag.Int("max-rooms", 100, "Most rooms there may be at once, a room is removed again when its last member leaves. 0 means no limit")
+
+// roomName is what a room may be called, it is also used as a file name for the history.
+var roomName = regexp.MustCompile(`^#[A-Za-z0-9_-]{1,32}$`)
+
+// room is a chat room. Every room has its own members, clock, sequence numbers and history.
+// Everything in it is guarded by the chatServers mutex.
+type room struct {
+	name        string
+	members     map[string]bool // client names of the participants in the room
+	clockMode   gRPC.ClockMode  // whether vectorClock or lamport is used
+	vectorClock vclock.Clock    // counts broadcasts per ClientID, the server is ClientID 0
+	lamport     vclock.Lamport  // the rooms clock in LAMPORT mode
+	retired     map[int32]bool  // ClientIDs of participants that have left, their slot is retired from the clock
+	joinedAt    map[int32]int32 // ClientID -> the servers slot of the clock when the participant joined, see stripRetired
+	sequence    int64           // number of the last broadcast, every member sees broadcasts in this order
+	history     historyStore    // the broadcasts, kept to resend, replay and page through them
+	skipped     map[int32]int32 // ClientID -> messages of the participant that were rejected, see skip
+	limiter     bucket          // limits how fast the members together may send
+}
+
+// room returns the named room, and makes it if it doesn't exist yet.
+// Sequence numbers continue after the newest broadcast in the rooms history.
+// The caller must hold s.mutex.
+func (s *chatServer) room(name string) (*room, error) {
+	if r, ok := s.rooms[name]; ok {
+		return r, nil
+	}
+	if !roomName.MatchString(name) {
+		return nil, status.Errorf(codes.InvalidArgument, "%q is not a room name, use # followed by up to 32 letters, digits, - or _", name)
+	}
+	// every room costs a history and a clock, so participants can't make them without end
+	if name != defaultRoom && *maxRooms > 0 && len(s.rooms) >= *maxRooms {
+		return nil, status.Errorf(codes.ResourceExhausted, "there are already %d rooms, join one of them or try again later", len(s.rooms))
+	}
+
+	history, err := s.openHistory(name)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not open the history of %s: %v", name, err)
+	}
+	r := &room{
+		name:        name,
+		members:     make(map[string]bool),
+		clockMode:   s.clockMode,
+		vectorClock: vclock.New(),
+		retired:     make(map[int32]bool),
+		joinedAt:    make(map[int32]int32),
+		skipped:     make(map[int32]int32),
+		sequence:    history.Last(),
+		history:     history,
+	}
+	s.rooms[name] = r
+	return r, nil
+}
+
+// memberRoom returns the room, if the named participant is in it. An empty room name means the default room.
+// The caller must hold s.mutex.
+func (s *chatServer) memberRoom(name string, roomName string) (*room, error) {
+	if roomName == "" {
+		roomName = defaultRoom
+	}
+	r, ok := s.rooms[roomName]
+	if !ok || !r.members[name] {
+		return nil, status.Errorf(codes.FailedPrecondition, "you are not in %s", roomName)
+	}
+	return r, nil
+}
+
+// joinRoom adds the participant to the room and tells the members that were already there.
+// It returns what the participant needs to follow the room from here on. since is the last broadcast
+// of the room the participant has seen, 0 for a new member that gets the latest -replay broadcasts.
+// The caller must hold s.mutex.
+func (s *chatServer) joinRoom(name string, roomName string, since int64) (*gRPC.RoomState, error) {
+	r, err := s.room(roomName)
+	if err != nil {
+		return nil, err
+	}
+	if r.members[name] {
+		return nil, status.Errorf(codes.AlreadyExists, "you are already in %s", roomName)
+	}
+	id := s.clientIDs[name]
+
+	// the replay is what happened before the client came, or what it missed while it was away
+	backlog := r.backlog(s.replay)
+	if since > 0 {
+		backlog = r.since(since)
+	}
+
+	// the join is an event of the server, the new client gets its slot once it sends something.
+	// A returning ClientID starts counting from zero again, like everyone else does when they see the join.
+	r.tickClock()
+	r.admit(int32(id))
+
+	r.event(chatlog.Join).Participant(name).ClientID(int32(id)).Infof("Participant %s joined %s at %s", name, r.name, r.clockString())
+
+	//Sends the join event to the other members
+	join := &gRPC.ChatMessage{
+		ClientID:   0,
+		ClientName: serverClientName,
+		Payload:    &gRPC.ChatMessage_Join{Join: &gRPC.Join{ClientName: name, ClientID: int32(id)}},
+	}
+	r.stampClock(join)
+	s.SendMessages(r, join)
+	s.metrics.joined(r.name)
+
+	// from here on the client gets every broadcast of the room, the join itself is covered by the state
+	r.members[name] = true
+
+	return &gRPC.RoomState{
+		Room:             r.name,
+		VectorClock:      r.vectorClock.Copy(),
+		LamportTimestamp: int32(r.lamport),
+		Sequence:         r.sequence,
+		Backlog:          backlog,
+	}, nil
+}
+
+// leaveRoom removes the participant from the room, broadcasts a Leave event to the remaining members
+// and retires its vector clock slot. The reason is empty when the participant left by itself.
+// The caller must hold s.mutex.
+func (s *chatServer) leaveRoom(name string, r *room, reason string) {
+	id := int32(s.clientIDs[name])
+
+	delete(r.members, name)
+	r.tickClock()
+
+	left := "left " + r.name
+	if reason != "" {
+		left = fmt.Sprintf("left %s (%s)", r.name, reason)
+	}
+	r.event(chatlog.Leave).Participant(name).ClientID(id).Attr(slog.String("reason", reason)).Infof("Participant %s %s at %s", name, left, r.clockString())
+
+	// send the leave event to all remaining members
+	leave := &gRPC.ChatMessage{
+		ClientID:   0,
+		ClientName: serverClientName,
+		Payload:    &gRPC.ChatMessage_Leave{Leave: &gRPC.Leave{ClientName: name, ClientID: id, Reason: reason}},
+	}
+	r.stampClock(leave)
+	s.SendMessages(r, leave)
+	s.metrics.left(r.name)
+
+	// the leave carried the participants last count, after that its slot is not needed anymore
+	r.retire(id)
+
+	if len(r.members) == 0 && r.name != defaultRoom {
+		s.removeRoom(r)
+	}
+}
+
+// removeRoom closes a room nobody is in anymore. A history in -history-dir is kept on disk,
+// so the room picks up where it was if someone joins it again, one in memory is gone.
+// The caller must hold s.mutex.
+func (s *chatServer) removeRoom(r *room) {
+	delete(s.rooms, r.name)
+	s.metrics.forgetRoom(r.name)
+	if err := r.history.Close(); err != nil {
+		chatlog.Event(chatlog.History).Room(r.name).Err(err).Errorf("Failed to close the history of %s: %v", r.name, err)
+	}
+	r.event(chatlog.Leave).Infof("Room %s is empty and removed", r.name)
+}
+
+// roomsOf returns the rooms the participant is in, ordered by name.
+// The caller must hold s.mutex.
+func (s *chatServer) roomsOf(name string) []*room {
+	var rooms []*room
+	for _, r := range s.rooms {
+		if r.members[name] {
+			rooms = append(rooms, r)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].name < rooms[j].name })
+	return rooms
+}
+
+// JoinRoom adds the participant of the session to a room, the room is made if it doesn't exist yet.
+func (s *chatServer) JoinRoom(ctx context.Context, in *gRPC.RoomRequest) (*gRPC.RoomState, error) {
+	name, _, err := s.sessionName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closing {
+		return nil, errShuttingDown
+	}
+	return s.joinRoom(name, in.Room, 0)
+}
+
+// LeaveRoom takes the participant of the session out of a room.
+func (s *chatServer) LeaveRoom(ctx context.Context, in *gRPC.RoomRequest) (*gRPC.Ack, error) {
+	name, _, err := s.sessionName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r, err := s.memberRoom(name, in.Room)
+	if err != nil {
+		return nil, err
+	}
+	s.leaveRoom(name, r, "")
+	return &gRPC.Ack{Message: fmt.Sprintf("You left %s", r.name)}, nil
+}
+
+// ListRooms returns every room with the names of its members.
+func (s *chatServer) ListRooms(ctx context.Context, in *gRPC.ListRoomsRequest) (*gRPC.RoomList, error) {
+	if _, _, err := s.sessionName(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := &gRPC.RoomList{}
+	for _, r := range s.rooms {
+		list.Rooms = append(list.Rooms, &gRPC.RoomInfo{Room: r.name, Participants: r.memberNames()})
+	}
+	sort.Slice(list.Rooms, func(i, j int) bool { return list.Rooms[i].Room < list.Rooms[j].Room })
+	return list, nil
+}
+
+// memberNames returns the names of the members, sorted.
+// The caller must hold s.mutex.
+func (r *room) memberNames() []string {
+	names := make([]string, 0, len(r.members))
+	for name := range r.members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// closeRooms closes the history of every room.
+func (s *chatServer) closeRooms() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, r := range s.rooms {
+		if err := r.history.Close(); err != nil {
+			chatlog.Event(chatlog.History).Room(r.name).Err(err).Errorf("Failed to close the history of %s: %v", r.name, err)
+		}
+	}
+}
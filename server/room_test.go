@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRoomLimit(t *testing.T) {
+	defer func(limit int) { *maxRooms = limit }(*maxRooms)
+	*maxRooms = 3
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(64, dropOldest))
+	c := ts.join(t, "alice")
+	if c == nil {
+		t.FailNow()
+	}
+
+	// #general counts too
+	for i := 1; i < *maxRooms; i++ {
+		if _, err := c.chat.JoinRoom(c.ctx, &gRPC.RoomRequest{Room: fmt.Sprintf("#room%d", i)}); err != nil {
+			t.Fatalf("could not make room %d: %v", i, err)
+		}
+	}
+	_, err := c.chat.JoinRoom(c.ctx, &gRPC.RoomRequest{Room: "#onetoomany"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("made a room past the limit, got %v", err)
+	}
+
+	// leaving a room makes room for another
+	if _, err := c.chat.LeaveRoom(c.ctx, &gRPC.RoomRequest{Room: "#room1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.chat.JoinRoom(c.ctx, &gRPC.RoomRequest{Room: "#onetoomany"}); err != nil {
+		t.Fatalf("could not make a room after one was removed: %v", err)
+	}
+}
+
+func TestEmptyRoomsAreRemoved(t *testing.T) {
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(64, dropOldest))
+	alice, bob := ts.join(t, "alice"), ts.join(t, "bob")
+	if alice == nil || bob == nil {
+		t.FailNow()
+	}
+	for _, c := range []*testClient{alice, bob} {
+		if _, err := c.chat.JoinRoom(c.ctx, &gRPC.RoomRequest{Room: "#dev"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exists := func(room string) bool {
+		ts.chat.mutex.Lock()
+		defer ts.chat.mutex.Unlock()
+		_, ok := ts.chat.rooms[room]
+		return ok
+	}
+
+	alice.chat.LeaveRoom(alice.ctx, &gRPC.RoomRequest{Room: "#dev"})
+	if !exists("#dev") {
+		t.Fatal("#dev was removed while bob is still in it")
+	}
+	bob.chat.LeaveRoom(bob.ctx, &gRPC.RoomRequest{Room: "#dev"})
+	if exists("#dev") {
+		t.Error("#dev is still there after everyone left")
+	}
+	// nor are its metrics, clients could otherwise grow the label count without limit
+	var scraped strings.Builder
+	ts.chat.writeMetrics(&scraped)
+	if strings.Contains(scraped.String(), `room="#dev"`) {
+		t.Errorf("the metrics still have series for #dev:\n%s", scraped.String())
+	}
+
+	// the default room stays, also when everyone is gone
+	alice.chat.DisconnectFromServer(alice.ctx, &gRPC.Session{ClientName: alice.name, SessionToken: alice.token})
+	bob.chat.DisconnectFromServer(bob.ctx, &gRPC.Session{ClientName: bob.name, SessionToken: bob.token})
+	if !exists(defaultRoom) {
+		t.Errorf("%s was removed", defaultRoom)
+	}
+}
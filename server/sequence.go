@@ -0,0 +1,136 @@
+package main
+
+import (
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// SendMessages gives the message the rooms next sequence number and queues it for every member, including
+// members that have joined but not opened their stream yet. The sender gets it too, so it sees
+// its own message in the same place of the total order as everyone else.
+// Every client gets the same copy, which is not changed anymore once it is queued.
+// The caller must hold s.mutex.
+func (s *chatServer) SendMessages(r *room, msg *gRPC.ChatMessage) {
+	start := time.Now()
+	msg = proto.Clone(msg).(*gRPC.ChatMessage)
+	msg.Room = r.name
+	r.sequenceMessage(msg)
+	for name := range r.members {
+		s.broadcaster.send(name, msg)
+	}
+	s.publish(msg)
+	s.metrics.broadcast(r.name, len(r.members), time.Since(start))
+}
+
+// sequenceMessage gives a broadcast the next number in the rooms total order, and keeps it in the history
+// so it can be resent and replayed.
+// The caller must hold s.mutex.
+func (r *room) sequenceMessage(msg *gRPC.ChatMessage) {
+	r.sequence++
+	msg.Sequence = r.sequence
+
+	if err := r.history.Append(msg); err != nil {
+		chatlog.Event(chatlog.History).Room(r.name).Sequence(msg.Sequence).Err(err).Errorf("Failed to keep broadcast %d of %s in the history: %v", msg.Sequence, r.name, err)
+	}
+}
+
+// resend queues the rooms broadcasts from..to for the named client again. Broadcasts that are
+// no longer in the history are reported back as a Resend, so the client can skip them.
+// The caller must hold s.mutex.
+func (s *chatServer) resend(name string, r *room, from int64, to int64) {
+	to = min(to, r.sequence)
+	kept, err := r.history.Range(from, to)
+	if err != nil {
+		chatlog.Event(chatlog.History).Room(r.name).Err(err).Errorf("Failed to read broadcasts %d to %d of %s from the history: %v", from, to, r.name, err)
+	}
+
+	next := from
+	for _, msg := range append(kept, nil) {
+		// anything between next and the next kept broadcast is gone
+		lost := to
+		if msg != nil {
+			lost = msg.Sequence - 1
+		}
+		if lost >= next {
+			chatlog.Event(chatlog.Ordering).Participant(name).Room(r.name).Warnf("Can't resend broadcasts %d to %d of %s to %s, they are no longer kept", next, lost, r.name, name)
+			s.broadcaster.send(name, &gRPC.ChatMessage{
+				ClientName: serverClientName,
+				Room:       r.name,
+				Payload:    &gRPC.ChatMessage_Resend{Resend: &gRPC.Resend{FromSequence: next, ToSequence: lost}},
+			})
+		}
+		if msg != nil {
+			s.broadcaster.send(name, msg)
+			next = msg.Sequence + 1
+		}
+	}
+}
+
+// historyPage is the most broadcasts one History call sends, so a client can't have the whole
+// history in -history-dir read into memory at once.
+const historyPage = 100
+
+// History sends up to limit broadcasts of the room numbered below beforeSequence, oldest first.
+// Clients page backwards by asking again with the sequence number of the oldest broadcast they got.
+func (s *chatServer) History(in *gRPC.HistoryRequest, stream gRPC.Chat_HistoryServer) error {
+	name, _, err := s.sessionName(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	r, err := s.memberRoom(name, in.Room)
+	if err != nil {
+		s.mutex.Unlock()
+		return err
+	}
+	last := r.sequence
+	history := r.history
+	s.mutex.Unlock()
+
+	if in.BeforeSequence > 0 {
+		last = min(last, in.BeforeSequence-1)
+	}
+	limit := int64(in.Limit)
+	if limit <= 0 {
+		limit = 20
+	}
+	limit = min(limit, historyPage)
+
+	page, err := history.Range(max(last-limit+1, 1), last)
+	if err != nil {
+		return err
+	}
+	for _, msg := range page {
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backlog returns the latest n broadcasts of the room, for replaying them to a client that has just joined.
+// The caller must hold s.mutex.
+func (r *room) backlog(n int) []*gRPC.ChatMessage {
+	if n <= 0 {
+		return nil
+	}
+	msgs, err := r.history.Range(max(r.sequence-int64(n)+1, 1), r.sequence)
+	if err != nil {
+		chatlog.Event(chatlog.History).Room(r.name).Err(err).Errorf("Failed to read the backlog of %s from the history: %v", r.name, err)
+	}
+	return msgs
+}
+
+// since returns the broadcasts of the room after the given one, for a client that lost its connection.
+// The caller must hold s.mutex.
+func (r *room) since(last int64) []*gRPC.ChatMessage {
+	msgs, err := r.history.Range(last+1, r.sequence)
+	if err != nil {
+		chatlog.Event(chatlog.History).Room(r.name).Err(err).Errorf("Failed to read the missed broadcasts of %s from the history: %v", r.name, err)
+	}
+	return msgs
+}
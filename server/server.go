@@ -1,23 +1,39 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"flag"
 	"fmt"
-	"hash/fnv"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	"github.com/JonasSkjodt/chitty-chat/content"
 	// this has to be the same as the go.mod module,
 	// followed by the path to the folder the proto file is in.
 	// inspired by https://github.com/PatrickMatthiesen/DSYS-gRPC-template and https://articles.wesionary.team/grpc-console-chat-application-in-go-dd77a29bb5c3
 	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// serverClientName is the name on messages the server sends itself, no participant may use it.
+const serverClientName = "Server"
+
 type chatServer struct {
 	gRPC.UnimplementedChatServer        // You need this line if you have a server
 	name                         string // Not required but useful if you want to name your server
@@ -25,27 +41,81 @@ type chatServer struct {
 
 	mutex sync.Mutex // used to lock the server to avoid race conditions.
 
+	// everything below is guarded by mutex
+	clientNames map[string]gRPC.Chat_MessageStreamServer // client name -> the clients stream
+	clientIDs   map[string]int                           // client name -> ClientID
+	sessions    map[string]string                        // session token -> client name
+	clientID    int                                      // the next ClientID to hand out
+	rooms       map[string]*room                         // room name -> room, a room is made when the first participant joins it
+	closing     bool                                     // set when the server shuts down, no one can join anymore
+	floods      map[string]*flood                        // client name -> how fast it sends, see checkFlood
+	connections map[string]connection                    // client name -> where and when it connected
+	bannedNames map[string]ban                           // client name -> its ban, see Admin.Ban
+	bannedIPs   map[string]ban                           // IP -> its ban
+	tails       map[*tail]bool                           // operators following the broadcasts, see Admin.Tail
+
+	clockMode      gRPC.ClockMode                          // whether vector clocks or lamport timestamps are used in the rooms
+	openHistory    func(room string) (historyStore, error) // opens the history of a new room
+	replay         int                                     // how many of the latest broadcasts of a room a new member gets
+	broadcaster    *broadcaster                            // the send queues of the connected clients
+	metrics        *metrics                                // what the server did, for -metrics
+	health         *health.Server                          // the Health service, see registerHealth
+	healthServices []string                                // the services the Health service reports on, besides the server as a whole
 }
 
 // flags are used to get arguments from the terminal. Flags take a value, a default value and a description of the flag.
 // to use a flag then just add it as an argument when running the program.
 var serverName = flag.String("name", "default", "Senders name") // set with "-name <name>" in terminal
 var port = flag.String("port", "5400", "Server port")           // set with "-port <port>" in terminal
-var vectorClock = []int32{0}
-var clientID = 1
-var nextNumClock = 0 // vector clock for the server
-
-// Maps
-var clientNames = make(map[string]gRPC.Chat_MessageStreamServer)
-var clientIDs = make(map[string]int)
+var listenAddr = flag.String("listen", "", "Address to listen on as host:port, e.g. 0.0.0.0:5400 for every interface. Default is localhost on -port")
+var advertiseAddr = flag.String("advertise", "", "Address clients should dial, printed at startup. Default is the listen address, or the outbound IP of this machine when listening on every interface")
+var queueSize = flag.Int("queue", 64, "Messages buffered per client before the overflow policy kicks in")
+var overflow = flag.String("overflow", "drop-oldest", "What to do when a client's queue is full: drop-oldest, disconnect or block")
+var statsInterval = flag.Duration("stats", 0, "How often to log send queue stats, 0 turns it off")
+var clockFlag = flag.String("clock", "vector", "Logical clock put on messages: vector, or lamport for a single number in large rooms")
+var historySize = flag.Int("history", 256, "How many of the latest broadcasts per room are kept in memory, when there is no -history-dir")
+var historyDir = flag.String("history-dir", "", "Folder with an append-only file per room that keeps every broadcast, also across restarts")
+var replayCount = flag.Int("replay", 20, "How many of the latest broadcasts of a room a client gets when it joins the room")
+var authFile = flag.String("auth-file", "", "File with a name:password line per participant, turns on password authentication")
+var authSecret = flag.String("auth-secret", "", "File with the secret bearer tokens are signed with, turns on token authentication")
+var issueToken = flag.String("issue-token", "", "Print a bearer token for this participant, signed with -auth-secret, and exit")
+var hashPasswordFor = flag.String("hash-password", "", "Ask for the password of this participant and print its line for -auth-file, then exit")
+var tokenTTL = flag.Duration("token-ttl", 24*time.Hour, "How long a token printed by -issue-token is valid")
+var shutdownTimeout = flag.Duration("shutdown-timeout", 10*time.Second, "How long a graceful shutdown may take before the remaining connections are closed")
+var shutdownReason = flag.String("shutdown-reason", "the server is shutting down", "Reason told to the clients when the server shuts down")
+var restartIn = flag.Duration("restart-in", 0, "Told to the clients on shutdown as when to expect the server back, 0 means it is not coming back")
+var logConfig = chatlog.Flags("log_server.txt")
+var maxLength = flag.Int("max-length", 128, "Most characters (not bytes) a message may have, longer ones are rejected")
+var keepaliveTime = flag.Duration("keepalive", 30*time.Second, "How long a client may be idle before the server pings it")
+var keepaliveTimeout = flag.Duration("keepalive-timeout", 10*time.Second, "How long to wait for a ping answer before the client counts as lost")
+var attachTimeout = flag.Duration("attach-timeout", 30*time.Second, "How long a client may take to open its message stream after connecting before its session is ended")
 
 func main() {
-
-	f := setLog() //uncomment this line to log to a log.txt file instead of the console
-	defer f.Close()
-
 	// This parses the flags and sets the correct/given corresponding values.
 	flag.Parse()
+
+	// only prints the token, so it can be piped into a file or a client flag
+	if *issueToken != "" {
+		printToken()
+		return
+	}
+	// only prints the line, so it can be appended to the password file
+	if *hashPasswordFor != "" {
+		if err := hashPassword(*hashPasswordFor); err != nil {
+			fmt.Printf("Failed to hash the password: %v \n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// log to the console and, as JSON lines, to a log file that is rotated instead of cleared
+	logs, err := chatlog.Setup(logConfig)
+	if err != nil {
+		fmt.Printf("Failed to set up logging: %v \n", err)
+		os.Exit(2)
+	}
+	defer logs.Close()
+
 	fmt.Println(".:server is starting:.")
 
 	// launch the server
@@ -54,169 +124,569 @@ func main() {
 	// code here is unreachable because launchServer occupies the current thread.
 }
 
-func launchServer() {
-	fmt.Printf("Server %s: Attempts to create listener on port %s\n", *serverName, *port)
-	log.Printf("Server %s: Attempts to create listener on port %s\n", *serverName, *port)
+// listen creates the tcp listener on -listen, or by default on localhost at -port.
+func listen() (net.Listener, error) {
+	address := *listenAddr
+	if address == "" {
+		address = net.JoinHostPort("localhost", *port)
+	}
+	serverEvent(chatlog.Startup).Infof("Server %s: Attempts to create listener on %s", *serverName, address)
+	return net.Listen("tcp", address)
+}
 
-	// Create listener tcp on given port or default port 5400
-	list, err := net.Listen("tcp", fmt.Sprintf("localhost:%s", *port))
+func launchServer() {
+	list, err := listen()
 	if err != nil {
-		fmt.Printf("Server %s: Failed to listen on port %s: %v \n", *serverName, *port, err)
-		log.Printf("Server %s: Failed to listen on port %s: %v", *serverName, *port, err) //If it fails to listen on the port, run launchServer method again with the next value/port in ports array
+		serverEvent(chatlog.Startup).Err(err).Errorf("Server %s: Failed to listen: %v", *serverName, err)
 		return
 	}
+	_, listenPort, _ := net.SplitHostPort(list.Addr().String())
 
 	// makes gRPC server using the options
-	// you can add options here if you want or remove the options part entirely
-	var opts []grpc.ServerOption
+	// keepalive pings let the server notice clients that vanished without closing their connection
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: *keepaliveTime, Timeout: *keepaliveTimeout}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 5 * time.Second, PermitWithoutStream: true}),
+		grpc.ForceServerCodec(lenientCodec{encoding.GetCodec("proto")}),
+	}
+
+	creds, err := serverTLS()
+	if err != nil {
+		serverEvent(chatlog.Startup).Err(err).Errorf("Server %s: Failed to set up TLS: %v", *serverName, err)
+		return
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+	mutualTLS := *tlsCA != ""
+
+	// with -auth-file, -auth-secret or mutual TLS every call has to be authenticated, without them the chat is open
+	var authenticators []authenticator
+	if *authFile != "" {
+		passwords, err := loadPasswordFile(*authFile)
+		if err != nil {
+			serverEvent(chatlog.Auth).Err(err).Errorf("Server %s: Failed to load passwords: %v", *serverName, err)
+			return
+		}
+		authenticators = append(authenticators, passwords)
+	}
+	if *authSecret != "" {
+		signer, err := loadTokenSigner(*authSecret)
+		if err != nil {
+			serverEvent(chatlog.Auth).Err(err).Errorf("Server %s: Failed to load the token secret: %v", *serverName, err)
+			return
+		}
+		authenticators = append(authenticators, signer)
+	}
+	// the Admin service has a token of its own, operators don't have to be participants
+	var admin *adminAuth
+	if *adminTokenFile != "" {
+		admin, err = loadAdminToken(*adminTokenFile)
+		if err != nil {
+			serverEvent(chatlog.Auth).Err(err).Errorf("Server %s: Failed to load the admin token: %v", *serverName, err)
+			return
+		}
+		opts = append(opts, grpc.ChainUnaryInterceptor(admin.unaryInterceptor), grpc.ChainStreamInterceptor(admin.streamInterceptor))
+	}
+	if len(authenticators) > 0 || mutualTLS {
+		a := newAuth(mutualTLS, authenticators...)
+		opts = append(opts, grpc.ChainUnaryInterceptor(a.unaryInterceptor), grpc.ChainStreamInterceptor(a.streamInterceptor))
+	} else {
+		serverEvent(chatlog.Auth).Warnf("Server %s: No -auth-file, -auth-secret or -tls-ca, anyone can join", *serverName)
+	}
 	grpcServer := grpc.NewServer(opts...)
 
+	policy, err := parseOverflowPolicy(*overflow)
+	if err != nil {
+		serverEvent(chatlog.Startup).Err(err).Errorf("Server %s: %v", *serverName, err)
+		return
+	}
+
+	// an unbuffered queue can't hold a message before the stream is attached, and a negative one can't be made
+	if *queueSize < 1 {
+		serverEvent(chatlog.Startup).Errorf("Server %s: -queue must be at least 1, got %d", *serverName, *queueSize)
+		return
+	}
+
+	mode, err := parseClockMode(*clockFlag)
+	if err != nil {
+		serverEvent(chatlog.Startup).Err(err).Errorf("Server %s: %v", *serverName, err)
+		return
+	}
+
+	// a history that keeps nothing would leave nothing to replay or resend
+	if *historyDir == "" && *historySize < 1 {
+		serverEvent(chatlog.Startup).Errorf("Server %s: -history must be at least 1, got %d", *serverName, *historySize)
+		return
+	}
+
+	// every room gets its own history, kept in memory or in <history-dir>/<room without the #>.log
+	openHistory := func(room string) (historyStore, error) {
+		return newRingHistory(*historySize), nil
+	}
+	if *historyDir != "" {
+		if err := os.MkdirAll(*historyDir, 0777); err != nil {
+			serverEvent(chatlog.History).Err(err).Errorf("Server %s: Failed to make history folder %s: %v", *serverName, *historyDir, err)
+			return
+		}
+		openHistory = func(room string) (historyStore, error) {
+			return openFileHistory(filepath.Join(*historyDir, strings.TrimPrefix(room, "#")+".log"))
+		}
+	}
+
 	// makes a new server instance using the name and port from the flags.
-	server := &chatServer{
-		name: *serverName,
-		port: *port,
+	server := newChatServer(*serverName, listenPort, mode, newBroadcaster(*queueSize, policy), openHistory, *replayCount)
+	defer server.closeRooms()
+	if *statsInterval > 0 {
+		go server.broadcaster.reportStats(*statsInterval)
+	}
+	if *metricsAddr != "" {
+		metricsServer, err := server.serveMetrics(*metricsAddr)
+		if err != nil {
+			serverEvent(chatlog.Startup).Err(err).Errorf("Server %s: Could not serve metrics at %s: %v", *serverName, *metricsAddr, err)
+			return
+		}
+		defer metricsServer.Close()
 	}
 
-	gRPC.RegisterChatServer(grpcServer, server) //Registers the server to the gRPC server.
+	registerServices(grpcServer, server, admin != nil, *reflectionFlag)
 
-	fmt.Printf("Server %s: Listening at %v \n", *serverName, list.Addr())
-	log.Printf("Server %s: Listening at %v \n", *serverName, list.Addr())
+	// SIGINT and SIGTERM stop the server gracefully
+	stopped := make(chan struct{})
+	go func() {
+		server.stopOnSignal(grpcServer, *shutdownReason, *restartIn, *shutdownTimeout)
+		close(stopped)
+	}()
 
-	if err := grpcServer.Serve(list); err != nil {
-		fmt.Printf("failed to serve %v", err)
-		log.Fatalf("failed to serve %v", err)
+	serverEvent(chatlog.Startup).Attr(slog.String("address", list.Addr().String())).Infof("Server %s: Listening at %v", *serverName, list.Addr())
+	serverEvent(chatlog.Startup).Infof("Server %s: Clients can join with -server %s", *serverName, advertise(list.Addr().(*net.TCPAddr)))
+
+	// the server reports SERVING once Serve takes connections on the listener
+	if err := grpcServer.Serve(&servingListener{Listener: list, ready: server.serving}); err != nil {
+		serverEvent(chatlog.Shutdown).Err(err).Fatalf("failed to serve %v", err)
+	}
+	// Serve returns as soon as the listener is closed, the streams may still be ending.
+	// Once they have, the deferred closeRooms writes out the histories.
+	<-stopped
+	serverEvent(chatlog.Shutdown).Infof("Server %s: Stopped", *serverName)
+}
+
+// serverEvent starts a log record about the server itself.
+func serverEvent(kind chatlog.Kind) *chatlog.Entry {
+	return chatlog.Event(kind).Attr(slog.String("server", *serverName))
+}
+
+// printToken prints a bearer token for the -issue-token participant.
+func printToken() {
+	if *authSecret == "" {
+		fmt.Println("-issue-token needs the -auth-secret the server uses")
+		os.Exit(2)
+	}
+	signer, err := loadTokenSigner(*authSecret)
+	if err != nil {
+		fmt.Printf("Failed to load the token secret: %v \n", err)
+		os.Exit(1)
 	}
-	// code here is unreachable because grpcServer.Serve occupies the current thread.
+	fmt.Println(signer.issue(*issueToken, time.Now().Add(*tokenTTL)))
 }
 
-func DeleteUser(clientName string) {
+// newChatServer makes a chat server with no participants and no rooms yet.
+// openHistory is called once for every room, when the room is made.
+func newChatServer(name string, port string, mode gRPC.ClockMode, b *broadcaster, openHistory func(string) (historyStore, error), replay int) *chatServer {
+	return &chatServer{
+		name:        name,
+		port:        port,
+		clientNames: make(map[string]gRPC.Chat_MessageStreamServer),
+		clientIDs:   make(map[string]int),
+		sessions:    make(map[string]string),
+		rooms:       make(map[string]*room),
+		floods:      make(map[string]*flood),
+		connections: make(map[string]connection),
+		bannedNames: make(map[string]ban),
+		bannedIPs:   make(map[string]ban),
+		tails:       make(map[*tail]bool),
+		clockMode:   mode,
+		clientID:    1,
+		openHistory: openHistory,
+		replay:      replay,
+		broadcaster: b,
+		metrics:     newMetrics(),
+	}
+}
+
+// DeleteUser removes the client from the clientNames, clientIDs and connections maps.
+// The caller must hold s.mutex.
+func (s *chatServer) DeleteUser(clientName string) {
 	if clientName != "" {
-		//Deletes the client from the clientNames map
-		delete(clientNames, clientName)
+		delete(s.clientNames, clientName)
+		delete(s.clientIDs, clientName)
+		delete(s.connections, clientName)
+		s.broadcaster.remove(clientName)
 	}
 }
 
-func (s *chatServer) MessageStream(msgStream gRPC.Chat_MessageStreamServer) error {
-	for {
-		// get the next message from the stream
-		msg, err := msgStream.Recv()
-		if err == io.EOF {
-			break
-		}
-		// some other error
-		if err != nil {
-			return err
-		}
-		hasher := fnv.New32()
-		hasher.Write([]byte(msg.ClientName))
-		if msg.Content == fmt.Sprint(hasher.Sum32()) {
-			clientNames[msg.ClientName] = msgStream
-			clientIDs[msg.ClientName] = clientID
-			clientID++
+// ConnectToServer registers a new participant, gives it a ClientID and puts it in the default room.
+// It returns the session token the client must use when opening its MessageStream.
+func (s *chatServer) ConnectToServer(ctx context.Context, in *gRPC.ClientName) (*gRPC.JoinReply, error) {
+	if err := checkName(ctx, in.ClientName); err != nil {
+		return nil, err
+	}
 
-			//Adds the client to the vector clock
-			vectorClock = append(vectorClock, 1)
-			UpdateVectorClock(msg.VectorClock)
+	token, err := newSessionToken()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not create session: %v", err)
+	}
 
-			fmt.Printf("Participant %s joined chitty-chat at lamport timestamp: %d \n", msg.ClientName, vectorClock)
-			log.Printf("Participant %s joined chitty-chat at lamport timestamp: %d", msg.ClientName, vectorClock)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-			//Sends the message that a client has connected to the other clients
-			SendMessages(&gRPC.ChatMessage{VectorClock: vectorClock, ClientID: int32(clientIDs[msg.ClientName]), ClientName: "Server", Content: fmt.Sprintf("Participant %s joined chitty-chat", msg.ClientName)})
+	if s.closing {
+		return nil, errShuttingDown
+	}
+	if err := s.checkBan(in.ClientName, peerAddress(ctx)); err != nil {
+		return nil, err
+	}
+	if err := s.checkFlooded(in.ClientName, time.Now()); err != nil {
+		return nil, err
+	}
+	// a second client with the same name would take over the messages of the first
+	if _, taken := s.clientIDs[in.ClientName]; taken {
+		return nil, status.Errorf(codes.AlreadyExists, "the name %q is already taken", in.ClientName)
+	}
 
-			hasher = nil
+	id := s.clientID
+	s.clientID++
+	s.register(in.ClientName, id, token, peerAddress(ctx))
 
-		} else if strings.Contains(msg.Content, "Participant "+msg.ClientName+" left chitty-chat") {
-			// Counts the clients vector clock up
-			UpdateVectorClock(msg.VectorClock)
+	state, err := s.joinRoom(in.ClientName, defaultRoom, 0)
+	if err != nil {
+		delete(s.sessions, token)
+		s.DeleteUser(in.ClientName)
+		return nil, err
+	}
 
-			fmt.Printf("Participant %s left chitty-chat at lamport timestamp: %d \n", msg.ClientName, vectorClock)
-			log.Printf("Participant %s left chitty-chat At lamport timestamp: %d", msg.ClientName, vectorClock)
+	return &gRPC.JoinReply{
+		ClientID:     int32(id),
+		SessionToken: token,
+		ClockMode:    s.clockMode,
+		Room:         state,
+		MaxLength:    int32(*maxLength),
+	}, nil
+}
 
-			//Adds the vector clock to the message
-			msg.VectorClock = vectorClock
+// checkName tells whether a participant may join under the name.
+func checkName(ctx context.Context, name string) error {
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "client name must not be empty")
+	}
+	if err := content.CheckName(name); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if strings.EqualFold(name, serverClientName) {
+		return status.Errorf(codes.InvalidArgument, "the name %q is reserved", name)
+	}
+	// with authentication on, participants can only join under the name they logged in with
+	if user, ok := authenticatedUser(ctx); ok && user != name {
+		return status.Errorf(codes.PermissionDenied, "logged in as %q, can't join as %q", user, name)
+	}
+	return nil
+}
 
-			DeleteUser(msg.ClientName)
+// register starts the session of a participant that has just connected from the address.
+// The caller must hold s.mutex.
+func (s *chatServer) register(name string, id int, token string, address string) {
+	s.clientIDs[name] = id
+	s.sessions[token] = name
+	s.connections[name] = connection{address: address, since: time.Now()}
 
-			// send the message to all clients
-			SendMessages(msg)
+	// from here on the client gets every broadcast of its rooms, they wait in its queue until it opens its stream.
+	s.broadcaster.add(name)
+	// a client that never opens its stream would keep the name taken and its queue filling up for good
+	time.AfterFunc(*attachTimeout, func() { s.dropUnattached(name, token) })
 
-		} else {
-			// Counts the clients vector clock up
-			UpdateVectorClock(msg.VectorClock)
+	chatlog.Event(chatlog.Connect).Participant(name).ClientID(int32(id)).Infof("Participant %s connected to chitty-chat", name)
+}
 
-			// the stream is closed so we can exit the loop
-			// log the message
-			fmt.Printf("Received message: from %s: \"%s\" At lamport timestamp: %d \n", msg.ClientName, msg.Content, vectorClock)
-			log.Printf("Received message: from %s: \"%s\" At lamport timestamp: %d", msg.ClientName, msg.Content, vectorClock)
+// DisconnectFromServer ends the session, removes the participant and tells the remaining clients.
+func (s *chatServer) DisconnectFromServer(ctx context.Context, in *gRPC.Session) (*gRPC.Ack, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-			//Adds the vector clock to the message
-			msg.VectorClock = vectorClock
+	name, ok := s.sessions[in.SessionToken]
+	if !ok || name != in.ClientName {
+		return nil, status.Error(codes.NotFound, "no session for this client")
+	}
+	if user, ok := authenticatedUser(ctx); ok && user != name {
+		return nil, status.Error(codes.PermissionDenied, "the session belongs to someone else")
+	}
+	s.leave(name, in.SessionToken, "")
 
-			// send the message to all clients
-			SendMessages(msg)
+	return &gRPC.Ack{Message: fmt.Sprintf("Goodbye %s", name)}, nil
+}
 
-		}
+// leave ends the session of the participant and takes it out of every room it is in,
+// the remaining members are told. The reason is empty when the participant left by itself.
+// The caller must hold s.mutex.
+func (s *chatServer) leave(name string, token string, reason string) {
+	for _, r := range s.roomsOf(name) {
+		s.leaveRoom(name, r, reason)
 	}
+	delete(s.sessions, token)
+	s.DeleteUser(name)
+	s.forgetFlood(name)
 
-	return nil
+	chatlog.Event(chatlog.Disconnect).Participant(name).Attr(slog.String("reason", reason)).Infof("Participant %s disconnected from chitty-chat", name)
 }
 
-func SendMessages(msg *gRPC.ChatMessage) {
-	for name := range clientNames {
-		if msg.ClientName != name {
-			vectorClock[0]++
-			msg.VectorClock = vectorClock
-			clientNames[name].Send(msg)
-		}
+// dropClient removes a participant whose stream ended without it leaving, unless it already left
+// or has attached a newer stream in the meantime.
+func (s *chatServer) dropClient(name string, token string, msgStream gRPC.Chat_MessageStreamServer, reason string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// when the server shuts down the streams end on purpose
+	if s.closing || s.sessions[token] != name || s.clientNames[name] != msgStream {
+		return
+	}
+	s.leave(name, token, reason)
+}
+
+// dropUnattached removes a participant that still hasn't opened its stream, unless the session
+// has ended in the meantime.
+func (s *chatServer) dropUnattached(name string, token string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closing || s.sessions[token] != name || s.clientNames[name] != nil {
+		return
 	}
+	chatlog.Event(chatlog.Disconnect).Participant(name).Warnf("Participant %s did not open its stream in time", name)
+	s.leave(name, token, "connection lost")
 }
 
-func UpdateVectorClock(msgVectorClock []int32) {
-	for i := 0; i < len(vectorClock); i++ {
-		// Add dummy values to msgVectorClock so that values can be compared
-		if len(msgVectorClock) <= len(vectorClock) {
-			var lenDiff int = len(vectorClock) - len(msgVectorClock)
-			for j := 0; j < lenDiff; j++ {
-				msgVectorClock = append(msgVectorClock, 0)
+// MessageStream attaches the stream to the session given in the "session-token" metadata
+// and handles every message received on it according to its payload.
+func (s *chatServer) MessageStream(msgStream gRPC.Chat_MessageStreamServer) error {
+	name, token, err := s.sessionName(msgStream.Context())
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	if s.closing {
+		s.mutex.Unlock()
+		return errShuttingDown
+	}
+	s.clientNames[name] = msgStream
+	queue := s.broadcaster.attach(name, msgStream)
+	s.mutex.Unlock()
+
+	// receive in the background, so the stream can also end when the send queue gives up
+	received := make(chan error, 1)
+	go func() {
+		for {
+			// get the next message from the stream
+			msg, err := msgStream.Recv()
+			if err != nil {
+				received <- err
+				return
+			}
+			s.metrics.receivedMessage(msg)
+			if done := s.handleMessage(name, token, msg); done {
+				received <- nil
+				return
 			}
 		}
-		// Compare and update vectorclock values
-		if vectorClock[i] < msgVectorClock[i] {
-			vectorClock[i] = msgVectorClock[i]
+	}()
+
+	// the stream ended without a Leave, either because the client is gone (closed stream,
+	// keepalive timeout or failed send) or because it couldn't keep up.
+	reason := "connection lost"
+	select {
+	case err = <-received:
+		if err == nil {
+			return nil
+		}
+	case <-queue.done:
+		err = queue.err
+		switch err {
+		case errSlowConsumer:
+			reason = "too slow"
+			err = status.Error(codes.ResourceExhausted, err.Error())
+		case errFlooding:
+			// like a kick, the client must not come back on its own, and ResumeSession would refuse it for a while
+			reason = "flooding"
+			err = status.Error(codes.PermissionDenied, err.Error())
+		default:
+			// kicked or banned, the client must not come back on its own
+			if removed, ok := err.(*removal); ok {
+				reason = removed.Error()
+				err = status.Error(codes.PermissionDenied, removed.Error())
+			}
 		}
 	}
-	vectorClock[0]++
+	s.dropClient(name, token, msgStream, reason)
+
+	if err == io.EOF {
+		return nil
+	}
+	return err
 }
 
-// Get preferred outbound ip of this machine
-// Usefull if you have to know which ip you should dial, in a client running on an other computer
-func GetOutboundIP() net.IP {
-	conn, err := net.Dial("udp", "8.8.8.8:80")
+// handleMessage acts on one message received from the named client, in the room it names.
+// It returns true when the client has left and its stream should end.
+func (s *chatServer) handleMessage(name string, token string, msg *gRPC.ChatMessage) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// the sender is whoever the stream belongs to, not whatever name the client put on the message
+	msg.ClientName = name
+	msg.ClientID = int32(s.clientIDs[name])
+
+	// a Leave ends the whole session, whichever room it was sent in
+	if _, ok := msg.Payload.(*gRPC.ChatMessage_Leave); ok {
+		if r, err := s.memberRoom(name, msg.Room); err == nil {
+			r.witnessClock(msg)
+		}
+		s.leave(name, token, "")
+		return true
+	}
+
+	// a message protobuf could not decode, see lenientCodec, we don't know its room so it is answered right here
+	if payload, ok := msg.Payload.(*gRPC.ChatMessage_Rejected); ok {
+		chatlog.Event(chatlog.Rejected).Participant(name).ClientID(msg.ClientID).Attr(slog.String("reason", payload.Rejected.Reason.String())).Infof("Rejected message from %s: %s", name, payload.Rejected.Detail)
+		msg.ClientName = serverClientName
+		msg.ClientID = 0
+		s.broadcaster.send(name, msg)
+		return false
+	}
+
+	r, err := s.memberRoom(name, msg.Room)
 	if err != nil {
-		log.Fatal(err)
+		chatlog.Event(chatlog.Rejected).Participant(name).ClientID(msg.ClientID).Room(msg.Room).Err(err).Infof("Ignored %T message from %s: %v", msg.Payload, name, err)
+		return false
 	}
-	defer conn.Close()
 
-	localAddr := conn.LocalAddr().(*net.UDPAddr)
+	switch payload := msg.Payload.(type) {
+	case *gRPC.ChatMessage_Text:
+		text, rejected := content.Clean(payload.Text.Content, *maxLength)
+		if rejected != nil {
+			chatlog.Event(chatlog.Rejected).Participant(name).ClientID(msg.ClientID).Room(r.name).Attr(slog.String("reason", rejected.Reason.String())).Infof("Rejected message from %s in %s: %s", name, r.name, rejected.Detail)
+			s.reject(name, r, msg, rejected)
+			return false
+		}
+		rejected, drop, disconnect := s.checkFlood(name, r, text, time.Now())
+		if rejected != nil || drop {
+			s.reject(name, r, msg, rejected)
+			if disconnect {
+				s.broadcaster.kick(name, errFlooding)
+			}
+			return false
+		}
+		payload.Text.Content = text
+
+		// learns what the client has seen, the message keeps the clients own clock
+		r.closeGap(msg)
+		r.stripRetired(msg)
+		r.witnessClock(msg)
+
+		// log the message
+		msg.Room = r.name
+		messageEvent(chatlog.Message, s.clockMode, msg).Infof("Received message: from %s in %s: \"%s\" At %s", msg.ClientName, r.name, payload.Text.Content, timestamp(s.clockMode, msg))
+
+		// send the message to all members of the room
+		s.SendMessages(r, msg)
+
+	case *gRPC.ChatMessage_Resend:
+		s.resend(name, r, payload.Resend.FromSequence, payload.Resend.ToSequence)
+
+	case *gRPC.ChatMessage_Presence:
+		// answers only the asking client with the names of everyone in the room
+		s.broadcaster.send(name, &gRPC.ChatMessage{
+			ClientName: serverClientName,
+			Room:       r.name,
+			Payload:    &gRPC.ChatMessage_Presence{Presence: &gRPC.Presence{Participants: r.memberNames()}},
+		})
+
+	default:
+		// joins only happen through ConnectToServer and JoinRoom
+		chatlog.Event(chatlog.Rejected).Participant(name).ClientID(msg.ClientID).Room(r.name).Infof("Ignored %T message from %s", payload, name)
+	}
+	return false
+}
 
-	return localAddr.IP
+// reject drops a Text instead of broadcasting it, and tells the sender why if there is a rejection.
+// The message must not move the clock of the room, see skip.
+// The caller must hold s.mutex.
+func (s *chatServer) reject(name string, r *room, msg *gRPC.ChatMessage, rejected *gRPC.Rejected) {
+	r.skip(msg)
+	if rejected == nil {
+		return
+	}
+	s.broadcaster.send(name, &gRPC.ChatMessage{
+		ClientName: serverClientName,
+		Room:       r.name,
+		Payload:    &gRPC.ChatMessage_Rejected{Rejected: rejected},
+	})
 }
 
-// sets the logger to use a log.txt file instead of the console
-func setLog() *os.File {
-	// Clears the log.txt file when a new server is started
-	if err := os.Truncate("log_server.txt", 0); err != nil {
-		fmt.Printf("Failed to truncate: %v \n", err)
-		log.Printf("Failed to truncate: %v", err)
+// sessionName looks up the participant and token of the session sent as stream metadata.
+func (s *chatServer) sessionName(ctx context.Context) (string, string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get("session-token")
+	if len(tokens) == 0 {
+		return "", "", status.Error(codes.Unauthenticated, "missing session-token, call ConnectToServer first")
 	}
 
-	// This connects to the log file/changes the output of the log informaiton to the log.txt file.
-	f, err := os.OpenFile("log_server.txt", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	name, ok := s.sessions[tokens[0]]
+	if !ok {
+		return "", "", status.Error(codes.Unauthenticated, "unknown session-token")
+	}
+	if user, ok := authenticatedUser(ctx); ok && user != name {
+		return "", "", status.Error(codes.PermissionDenied, "the session belongs to someone else")
+	}
+	return name, tokens[0], nil
+}
+
+// newSessionToken returns a random hex encoded token identifying a session.
+func newSessionToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// advertise returns the address clients should dial to reach the listener.
+func advertise(listening *net.TCPAddr) string {
+	if *advertiseAddr != "" {
+		return *advertiseAddr
+	}
+	if !listening.IP.IsUnspecified() {
+		return listening.String()
+	}
+	// listening on every interface, the outbound IP is the one other machines can most likely reach
+	ip, err := GetOutboundIP()
+	if err != nil {
+		serverEvent(chatlog.Startup).Err(err).Warnf("Server %s: Failed to find the outbound IP, set -advertise: %v", *serverName, err)
+		return net.JoinHostPort("localhost", strconv.Itoa(listening.Port))
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(listening.Port))
+}
+
+// Get preferred outbound ip of this machine
+// Usefull if you have to know which ip you should dial, in a client running on an other computer
+func GetOutboundIP() (net.IP, error) {
+	// nothing is sent, dialing udp only picks the interface a packet would leave from
+	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
-		fmt.Printf("error opening file: %v", err)
-		log.Fatalf("error opening file: %v", err)
+		return nil, err
 	}
-	log.SetOutput(f)
-	return f
+	defer conn.Close()
+
+	localAddr := conn.LocalAddr().(*net.UDPAddr)
+
+	return localAddr.IP, nil
 }
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestMain(m *testing.M) {
+	// the tests send far faster than any person, and the log would only drown the test output
+	*rate, *roomRate, *repeatLimit = 0, 0, 0
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// testServer is a chat server on an in-process connection.
+type testServer struct {
+	chat *chatServer
+	grpc *grpc.Server
+	conn *grpc.ClientConn
+}
+
+// startTestServer serves a chat server with in-memory histories on a bufconn listener, it is stopped when the test ends.
+func startTestServer(t *testing.T, mode gRPC.ClockMode, b *broadcaster, opts ...grpc.ServerOption) *testServer {
+	t.Helper()
+	list := bufconn.Listen(1 << 20)
+	ts := &testServer{
+		chat: newChatServer("test", "0", mode, b, func(string) (historyStore, error) { return newRingHistory(256), nil }, 20),
+		grpc: grpc.NewServer(opts...),
+	}
+	registerServices(ts.grpc, ts.chat, true, true)
+	go ts.grpc.Serve(&servingListener{Listener: list, ready: ts.chat.serving})
+	t.Cleanup(ts.grpc.Stop)
+
+	ts.conn = dialBufconn(t, list)
+	return ts
+}
+
+// dialBufconn connects to the server on list, the connection is closed when the test ends.
+func dialBufconn(t *testing.T, list *bufconn.Listener) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return list.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// testClient is a participant of a testServer, everything it receives goes into received.
+type testClient struct {
+	name     string
+	id       int32
+	token    string
+	chat     gRPC.ChatClient
+	ctx      context.Context // carries the session-token
+	stream   gRPC.Chat_MessageStreamClient
+	received chan *gRPC.ChatMessage
+	err      error // why the stream ended, set before received is closed
+}
+
+// join connects a participant and opens its stream.
+func (ts *testServer) join(t *testing.T, name string) *testClient {
+	chat := gRPC.NewChatClient(ts.conn)
+	reply, err := chat.ConnectToServer(context.Background(), &gRPC.ClientName{ClientName: name})
+	if err != nil {
+		t.Errorf("%s could not connect: %v", name, err)
+		return nil
+	}
+	c := &testClient{
+		name:     name,
+		id:       reply.ClientID,
+		token:    reply.SessionToken,
+		chat:     chat,
+		ctx:      metadata.AppendToOutgoingContext(context.Background(), "session-token", reply.SessionToken),
+		received: make(chan *gRPC.ChatMessage, 4096),
+	}
+	c.stream, err = chat.MessageStream(c.ctx)
+	if err != nil {
+		t.Errorf("%s could not open its stream: %v", name, err)
+		return nil
+	}
+	go func() {
+		defer close(c.received)
+		for {
+			msg, err := c.stream.Recv()
+			if err != nil {
+				c.err = err
+				return
+			}
+			c.received <- msg
+		}
+	}()
+	return c
+}
+
+// say sends a Text to the room.
+func (c *testClient) say(room string, text string) error {
+	return c.stream.Send(&gRPC.ChatMessage{Room: room, Payload: &gRPC.ChatMessage_Text{Text: &gRPC.Text{Content: text}}})
+}
+
+// waitFor reads what the client received until one message matches, it fails the test after a while.
+func (c *testClient) waitFor(t *testing.T, what string, match func(*gRPC.ChatMessage) bool) {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case msg, ok := <-c.received:
+			if !ok {
+				t.Errorf("%s: stream ended while waiting for %s", c.name, what)
+				return
+			}
+			if match(msg) {
+				return
+			}
+		case <-timeout:
+			t.Errorf("%s: timed out waiting for %s", c.name, what)
+			return
+		}
+	}
+}
+
+// waitUntil polls the condition until it holds, it fails the test after a while.
+func waitUntil(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); !condition(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestConcurrentClients has dozens of clients join, chat in two rooms and leave at the same time.
+// Run it with -race, it is about the locking more than about what is delivered.
+func TestConcurrentClients(t *testing.T) {
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(1024, dropOldest))
+
+	const clients, messages = 40, 10
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := ts.join(t, fmt.Sprintf("client%d", i))
+			if c == nil {
+				return
+			}
+			if _, err := c.chat.JoinRoom(c.ctx, &gRPC.RoomRequest{Room: "#dev"}); err != nil {
+				t.Errorf("%s could not join #dev: %v", c.name, err)
+				return
+			}
+			for j := 0; j < messages; j++ {
+				if err := c.say(defaultRoom, fmt.Sprintf("%s says %d", c.name, j)); err != nil {
+					t.Errorf("%s could not send: %v", c.name, err)
+					return
+				}
+				c.say("#dev", fmt.Sprintf("%s in dev %d", c.name, j))
+			}
+			c.stream.Send(&gRPC.ChatMessage{Room: "#dev", Payload: &gRPC.ChatMessage_Presence{Presence: &gRPC.Presence{}}})
+			if _, err := c.chat.ListRooms(c.ctx, &gRPC.ListRoomsRequest{}); err != nil {
+				t.Errorf("%s could not list the rooms: %v", c.name, err)
+			}
+
+			// everyone gets its own messages back, in the order it sent them
+			last := fmt.Sprintf("%s says %d", c.name, messages-1)
+			c.waitFor(t, last, func(msg *gRPC.ChatMessage) bool {
+				return msg.ClientID == c.id && msg.Room == defaultRoom && msg.GetText().GetContent() == last
+			})
+
+			switch i % 3 {
+			case 0:
+				c.stream.Send(&gRPC.ChatMessage{Room: defaultRoom, Payload: &gRPC.ChatMessage_Leave{Leave: &gRPC.Leave{}}})
+			case 1:
+				if _, err := c.chat.DisconnectFromServer(context.Background(), &gRPC.Session{ClientName: c.name, SessionToken: c.token}); err != nil {
+					t.Errorf("%s could not disconnect: %v", c.name, err)
+				}
+			default:
+				// the stream just ends, like when the connection is lost
+			}
+			c.stream.CloseSend()
+		}(i)
+	}
+	wg.Wait()
+
+	waitUntil(t, "every client is gone", func() bool {
+		ts.chat.mutex.Lock()
+		defer ts.chat.mutex.Unlock()
+		return len(ts.chat.clientIDs) == 0 && len(ts.chat.sessions) == 0
+	})
+	ts.chat.mutex.Lock()
+	defer ts.chat.mutex.Unlock()
+	for name, r := range ts.chat.rooms {
+		if len(r.members) != 0 {
+			t.Errorf("%s still has members %v", name, r.memberNames())
+		}
+	}
+}
+
+// TestIdenticalTranscripts has everyone in a room talk at once, and checks that every member
+// got the same broadcasts in the same order, with either clock.
+func TestIdenticalTranscripts(t *testing.T) {
+	for _, mode := range []gRPC.ClockMode{gRPC.ClockMode_VECTOR, gRPC.ClockMode_LAMPORT} {
+		t.Run(mode.String(), func(t *testing.T) { identicalTranscripts(t, mode) })
+	}
+}
+
+func identicalTranscripts(t *testing.T, mode gRPC.ClockMode) {
+	ts := startTestServer(t, mode, newBroadcaster(1024, dropOldest))
+
+	const clients, messages = 12, 20
+	members := make([]*testClient, clients)
+	for i := range members {
+		if members[i] = ts.join(t, fmt.Sprintf("client%d", i)); members[i] == nil {
+			t.FailNow()
+		}
+	}
+
+	var wg sync.WaitGroup
+	transcripts := make([][]string, clients)
+	for i, c := range members {
+		wg.Add(1)
+		go func(i int, c *testClient) {
+			defer wg.Done()
+			for j := 0; j < messages; j++ {
+				if err := c.say(defaultRoom, fmt.Sprintf("%s says %d", c.name, j)); err != nil {
+					t.Errorf("%s could not send: %v", c.name, err)
+					return
+				}
+			}
+			// only the texts count, the members that joined first also saw the joins after theirs
+			var transcript []string
+			var last int64
+			c.waitFor(t, "every message", func(msg *gRPC.ChatMessage) bool {
+				if msg.Sequence != 0 && msg.Sequence <= last {
+					t.Errorf("%s got broadcast %d after %d", c.name, msg.Sequence, last)
+				}
+				last = msg.Sequence
+				if msg.GetText() != nil {
+					transcript = append(transcript, fmt.Sprintf("%d %s: %s", msg.Sequence, msg.ClientName, msg.GetText().Content))
+				}
+				return len(transcript) == clients*messages
+			})
+			transcripts[i] = transcript
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, transcript := range transcripts[1:] {
+		if len(transcript) != len(transcripts[0]) {
+			t.Fatalf("%s got %d messages, %s got %d", members[i+1].name, len(transcript), members[0].name, len(transcripts[0]))
+		}
+		for j := range transcript {
+			if transcript[j] != transcripts[0][j] {
+				t.Fatalf("message %d: %s got %q, %s got %q", j, members[i+1].name, transcript[j], members[0].name, transcripts[0][j])
+			}
+		}
+	}
+}
+
+// Names that could pass for the server or for someone else are refused.
+func TestCheckName(t *testing.T) {
+	for _, name := range []string{"", "Server", "server", "Server ", " Server", "Ser ver", "Server\u200b", "Server\u00a0", "\u202eSerVer", "\x1b[1mServer", "bob\tsmith", "alice\n", "\xff"} {
+		if err := checkName(context.Background(), name); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%q: got %v, want InvalidArgument", name, err)
+		}
+	}
+	for _, name := range []string{"alice", "Bjørn", "client-7", "Servers", "张伟"} {
+		if err := checkName(context.Background(), name); err != nil {
+			t.Errorf("%q: %v", name, err)
+		}
+	}
+}
+
+// A client that connects but never opens its stream must not keep its name, its room or its queue for good.
+func TestUnattachedSessionExpires(t *testing.T) {
+	defer func(timeout time.Duration) { *attachTimeout = timeout }(*attachTimeout)
+	*attachTimeout = 50 * time.Millisecond
+	ts := startTestServer(t, gRPC.ClockMode_VECTOR, newBroadcaster(64, dropOldest))
+
+	alice := ts.join(t, "alice")
+	chat := gRPC.NewChatClient(ts.conn)
+	if _, err := chat.ConnectToServer(context.Background(), &gRPC.ClientName{ClientName: "ghost"}); err != nil {
+		t.Fatal(err)
+	}
+	alice.waitFor(t, "the ghost leaving", func(msg *gRPC.ChatMessage) bool {
+		return msg.GetLeave().GetClientName() == "ghost"
+	})
+
+	ts.chat.mutex.Lock()
+	_, member := ts.chat.rooms[defaultRoom].members["ghost"]
+	_, aliceMember := ts.chat.rooms[defaultRoom].members["alice"]
+	ts.chat.mutex.Unlock()
+	ts.chat.broadcaster.mutex.Lock()
+	_, queued := ts.chat.broadcaster.queues["ghost"]
+	ts.chat.broadcaster.mutex.Unlock()
+	if member || queued {
+		t.Errorf("the ghost is still a member (%v) or has a queue (%v)", member, queued)
+	}
+	if !aliceMember {
+		t.Error("alice opened her stream, she should still be there")
+	}
+	if _, err := chat.ConnectToServer(context.Background(), &gRPC.ClientName{ClientName: "ghost"}); err != nil {
+		t.Errorf("the name is still taken: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/JonasSkjodt/chitty-chat/chatlog"
+	gRPC "github.com/JonasSkjodt/chitty-chat/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errShuttingDown is returned to clients that try to join while the server is stopping.
+var errShuttingDown = status.Error(codes.Unavailable, "the server is shutting down")
+
+// stopOnSignal shuts the server down gracefully on SIGINT or SIGTERM. A second signal stops it at once.
+func (s *chatServer) stopOnSignal(grpcServer *grpc.Server, reason string, restartIn time.Duration, timeout time.Duration) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	sig := <-signals
+	serverEvent(chatlog.Shutdown).Infof("Server %s: Received %v, shutting down", s.name, sig)
+
+	deadline := time.Now().Add(timeout)
+	s.shutdown(reason, restartIn, deadline)
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Until(deadline)):
+		serverEvent(chatlog.Shutdown).Warnf("Server %s: Still not stopped after %v, closing the remaining connections", s.name, timeout)
+		grpcServer.Stop()
+	case sig = <-signals:
+		serverEvent(chatlog.Shutdown).Warnf("Server %s: Received %v again, closing the remaining connections", s.name, sig)
+		grpcServer.Stop()
+	}
+}
+
+// shutdown tells every client that the server is going away and stops taking new participants.
+// Then it sends out whatever is still queued, which ends the streams. It returns once every queue
+// is drained or the deadline has passed.
+func (s *chatServer) shutdown(reason string, restartIn time.Duration, deadline time.Time) {
+	// probes see it first, so no new clients are sent this way
+	if s.health != nil {
+		s.health.Shutdown()
+	}
+
+	s.mutex.Lock()
+	s.closing = true
+	s.closeTails()
+	notice := &gRPC.ChatMessage{
+		ClientName: serverClientName,
+		Payload:    &gRPC.ChatMessage_Shutdown{Shutdown: &gRPC.Shutdown{Reason: reason, RestartIn: int32(restartIn.Seconds())}},
+	}
+	for name := range s.clientIDs {
+		s.broadcaster.send(name, notice)
+	}
+	serverEvent(chatlog.Shutdown).Infof("Server %s: Told %d participants: %s", s.name, len(s.clientIDs), reason)
+	s.mutex.Unlock()
+
+	if !s.broadcaster.drain(deadline) {
+		serverEvent(chatlog.Shutdown).Warnf("Server %s: Not every send queue could be drained in time", s.name)
+	}
+}
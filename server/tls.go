@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var tlsCert = flag.String("tls-cert", "", "Certificate file of the server, turns on TLS together with -tls-key")
+var tlsKey = flag.String("tls-key", "", "Private key file of the server certificate")
+var tlsCA = flag.String("tls-ca", "", "CA file that client certificates must be signed by, turns on mutual TLS")
+
+// serverTLS returns the transport credentials from the -tls flags, or nil when TLS is off.
+// With -tls-ca every client has to show a certificate signed by that CA.
+func serverTLS() (credentials.TransportCredentials, error) {
+	if *tlsCert == "" && *tlsKey == "" {
+		if *tlsCA != "" {
+			return nil, fmt.Errorf("-tls-ca needs -tls-cert and -tls-key")
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if *tlsCA != "" {
+		pem, err := os.ReadFile(*tlsCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", *tlsCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// certificateName returns the common name of the verified client certificate of the call, if there is one.
+func certificateName(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	name := info.State.VerifiedChains[0][0].Subject.CommonName
+	return name, name != ""
+}
@@ -0,0 +1,41 @@
+package vclock
+
+import "fmt"
+
+// Lamport is a scalar Lamport clock. It is a single number no matter how many participants there are,
+// but unlike a Clock it can't tell concurrent events apart.
+type Lamport int32
+
+// Tick counts a local event and returns its timestamp.
+func (l *Lamport) Tick() int32 {
+	*l++
+	return int32(*l)
+}
+
+// Witness moves the clock past a received timestamp, receiving counts as an event.
+func (l *Lamport) Witness(t int32) int32 {
+	if Lamport(t) > *l {
+		*l = Lamport(t)
+	}
+	return l.Tick()
+}
+
+// Stamp is a Lamport timestamp together with the ID of the participant that made it.
+// Ordering stamps by time and then by ID gives a total order that respects happens-before.
+type Stamp struct {
+	Time int32
+	ID   int32
+}
+
+// Less tells whether a comes before b in the total order.
+func (a Stamp) Less(b Stamp) bool {
+	if a.Time != b.Time {
+		return a.Time < b.Time
+	}
+	return a.ID < b.ID
+}
+
+// String prints the stamp as time.ID, e.g. 12.3 for time 12 at participant 3.
+func (a Stamp) String() string {
+	return fmt.Sprintf("%d.%d", a.Time, a.ID)
+}
@@ -0,0 +1,113 @@
+// Package vclock implements vector clocks keyed by stable participant IDs.
+//
+// A Clock maps a participant ID to the number of events that participant has done.
+// A missing ID counts as 0, so clocks of different sizes can always be compared and merged.
+package vclock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Clock is a vector clock, participant ID -> event count.
+type Clock map[int32]int32
+
+// Ordering is how two clocks relate in happens-before order.
+type Ordering int
+
+const (
+	Equal      Ordering = iota // both clocks have seen exactly the same events
+	Before                     // the first clock happened before the second
+	After                      // the first clock happened after the second
+	Concurrent                 // neither clock has seen all of the others events
+)
+
+func (o Ordering) String() string {
+	switch o {
+	case Equal:
+		return "equal"
+	case Before:
+		return "before"
+	case After:
+		return "after"
+	case Concurrent:
+		return "concurrent"
+	}
+	return fmt.Sprintf("Ordering(%d)", int(o))
+}
+
+// New returns an empty clock.
+func New() Clock {
+	return Clock{}
+}
+
+// Tick counts one event done by the participant with the given ID.
+func (c Clock) Tick(id int32) {
+	c[id]++
+}
+
+// Merge sets every entry to the max of c and other, so c has seen everything other has seen.
+func (c Clock) Merge(other Clock) {
+	for id, n := range other {
+		if n > c[id] {
+			c[id] = n
+		}
+	}
+}
+
+// Copy returns a clock with the same entries that doesn't share memory with c.
+func (c Clock) Copy() Clock {
+	cp := make(Clock, len(c))
+	for id, n := range c {
+		cp[id] = n
+	}
+	return cp
+}
+
+// Compare tells whether c happened before, after, concurrently with or at the same time as other.
+func (c Clock) Compare(other Clock) Ordering {
+	less, greater := false, false
+	for id, n := range c {
+		if n > other[id] {
+			greater = true
+		} else if n < other[id] {
+			less = true
+		}
+	}
+	for id, n := range other {
+		if _, ok := c[id]; !ok && n > 0 {
+			less = true
+		}
+	}
+
+	switch {
+	case less && greater:
+		return Concurrent
+	case less:
+		return Before
+	case greater:
+		return After
+	}
+	return Equal
+}
+
+// Retire removes the participant from the clock, once it has left it has no more events to count.
+func (c Clock) Retire(id int32) {
+	delete(c, id)
+}
+
+// String prints the clock ordered by ID, e.g. [0:3 1:2 4:1].
+func (c Clock) String() string {
+	ids := make([]int32, 0, len(c))
+	for id := range c {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	entries := make([]string, len(ids))
+	for i, id := range ids {
+		entries[i] = fmt.Sprintf("%d:%d", id, c[id])
+	}
+	return "[" + strings.Join(entries, " ") + "]"
+}
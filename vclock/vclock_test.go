@@ -0,0 +1,180 @@
+package vclock
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// clockOf turns a random map into a clock with few IDs and small counts, so the clocks quick
+// generates share IDs and often overlap, instead of almost always being concurrent.
+func clockOf(m map[uint8]uint8) Clock {
+	c := New()
+	for id, n := range m {
+		c[int32(id%4)] = int32(n % 5)
+	}
+	return c
+}
+
+// seenBy tells whether b has seen everything a has seen.
+func seenBy(a, b Clock) bool {
+	o := a.Compare(b)
+	return o == Before || o == Equal
+}
+
+func check(t *testing.T, property any) {
+	t.Helper()
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCompareIsConsistent(t *testing.T) {
+	flipped := map[Ordering]Ordering{Equal: Equal, Before: After, After: Before, Concurrent: Concurrent}
+	check(t, func(a, b map[uint8]uint8) bool {
+		x, y := clockOf(a), clockOf(b)
+		return x.Compare(x) == Equal && y.Compare(x) == flipped[x.Compare(y)]
+	})
+}
+
+func TestMissingCountsAsZero(t *testing.T) {
+	check(t, func(a map[uint8]uint8, id int32) bool {
+		x := clockOf(a)
+		padded := x.Copy()
+		if _, ok := padded[id]; !ok {
+			padded[id] = 0
+		}
+		return x.Compare(padded) == Equal && padded.Compare(x) == Equal
+	})
+}
+
+func TestTickHappensAfter(t *testing.T) {
+	check(t, func(a map[uint8]uint8, id int32) bool {
+		before := clockOf(a)
+		after := before.Copy()
+		after.Tick(id)
+		return before.Compare(after) == Before && after.Compare(before) == After
+	})
+}
+
+func TestMergeIsLeastUpperBound(t *testing.T) {
+	check(t, func(a, b, c map[uint8]uint8) bool {
+		x, y, z := clockOf(a), clockOf(b), clockOf(c)
+		merged := x.Copy()
+		merged.Merge(y)
+
+		// merged has seen everything x and y have seen
+		if !seenBy(x, merged) || !seenBy(y, merged) {
+			return false
+		}
+		// and nothing more: any clock that has seen both x and y has seen merged too
+		if seenBy(x, z) && seenBy(y, z) && !seenBy(merged, z) {
+			return false
+		}
+		// the order of merging doesn't matter
+		other := y.Copy()
+		other.Merge(x)
+		return merged.Compare(other) == Equal
+	})
+}
+
+func TestMergeDoesNotShareMemory(t *testing.T) {
+	x := Clock{1: 1}
+	y := x.Copy()
+	y.Tick(1)
+	x.Merge(Clock{2: 3})
+	if x.Compare(Clock{1: 1, 2: 3}) != Equal || y.Compare(Clock{1: 2}) != Equal {
+		t.Errorf("copies share memory: x %v, y %v", x, y)
+	}
+}
+
+// event is something a participant did in a simulated run, with the events it knows about.
+type event struct {
+	clock Clock
+	seen  map[int]bool // indexes of the events that happened before this one
+}
+
+// TestCompareMatchesHappensBefore simulates participants that do local events and send each other messages,
+// the way the chat does: ticking before sending, and merging then ticking on receive.
+// Compare must say Before exactly when one event could have influenced the other.
+func TestCompareMatchesHappensBefore(t *testing.T) {
+	check(t, func(steps []uint16) bool {
+		const participants = 4
+		clocks := make([]Clock, participants)
+		latest := make([]int, participants) // index of each participants latest event, -1 for none
+		for p := range clocks {
+			clocks[p] = New()
+			latest[p] = -1
+		}
+		var events []event
+
+		record := func(p int, also int) {
+			seen := make(map[int]bool)
+			for _, prev := range []int{latest[p], also} {
+				if prev < 0 {
+					continue
+				}
+				seen[prev] = true
+				for e := range events[prev].seen {
+					seen[e] = true
+				}
+			}
+			latest[p] = len(events)
+			events = append(events, event{clock: clocks[p].Copy(), seen: seen})
+		}
+
+		for _, step := range steps {
+			from, to := int(step%participants), int(step/participants%participants)
+			if from == to || step&0x100 == 0 {
+				// a local event, like sending a message nobody receives
+				clocks[from].Tick(int32(from))
+				record(from, -1)
+				continue
+			}
+			clocks[from].Tick(int32(from))
+			record(from, -1)
+			sent := latest[from]
+			clocks[to].Merge(events[sent].clock)
+			clocks[to].Tick(int32(to))
+			record(to, sent)
+		}
+
+		for i, a := range events {
+			for j, b := range events {
+				var want Ordering
+				switch {
+				case i == j:
+					want = Equal
+				case b.seen[i]:
+					want = Before
+				case a.seen[j]:
+					want = After
+				default:
+					want = Concurrent
+				}
+				if got := a.clock.Compare(b.clock); got != want {
+					t.Logf("event %d %v vs event %d %v: got %v, want %v", i, a.clock, j, b.clock, got, want)
+					return false
+				}
+			}
+		}
+		return true
+	})
+}
+
+func TestLamportWitness(t *testing.T) {
+	check(t, func(start, received int16) bool {
+		l := Lamport(start)
+		now := l.Witness(int32(received))
+		return now > int32(start) && now > int32(received) && Lamport(now) == l
+	})
+}
+
+func TestStampOrderIsTotal(t *testing.T) {
+	check(t, func(a, b Stamp) bool {
+		if a == b {
+			return !a.Less(b)
+		}
+		// exactly one of them comes first
+		return a.Less(b) != b.Less(a)
+	})
+}